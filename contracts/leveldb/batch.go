@@ -0,0 +1,124 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// DefaultMaxBatch limit of batch operations when queue doesn't configure it
+const DefaultMaxBatch = 100
+
+// maxBatch return limit of elements handled by single batch operation
+func (m *QueueMeta) maxBatch() int {
+	if m.Config.MaxBatch > 0 {
+		return m.Config.MaxBatch
+	}
+
+	return DefaultMaxBatch
+}
+
+// checkBatch validate size of batch against queue limit
+func checkBatch(l *list, n int) error {
+	switch {
+	case n <= 0:
+		return fmt.Errorf("batch size should be positive")
+	case n > l.meta.maxBatch():
+		return fmt.Errorf("batch size %d exceeds limit %d", n, l.meta.maxBatch())
+	}
+
+	return nil
+}
+
+// PushBackBatch create new queue element from every extra context of JSON array atomically.
+// @jsonArray - example: [{"country":"BY"},{"country":"RU"}]
+func (s *SimpleQueueContract) PushBackBatch(ctx TransactionContextInterface, queue, jsonArray string) ([]Query, error) {
+	var contexts []Context
+	if err := json.Unmarshal([]byte(jsonArray), &contexts); err != nil {
+		return nil, fmt.Errorf("parameter should be JSON array of objects: %w", err)
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPush); err != nil {
+		return nil, err
+	}
+
+	if err = checkBatch(l, len(contexts)); err != nil {
+		return nil, err
+	}
+
+	items := make([]SimpleQueue, 0, len(contexts))
+
+	for _, c := range contexts {
+		item, err := newItem(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+
+		if c != nil {
+			item.Context = c
+		}
+
+		items = append(items, item)
+	}
+
+	return s.pushItems(ctx, queue, items)
+}
+
+// PopFrontN extract and remove up to n first visible elements of queue atomically. Leased elements are skipped
+func (s *SimpleQueueContract) PopFrontN(ctx TransactionContextInterface, queue string, n int) ([]Query, error) {
+	return s.popN(ctx, queue, n, s.front)
+}
+
+// PopN extract and remove up to n last elements of queue atomically. Result starts from the last element.
+// Scheduled, expired and leased elements are skipped
+func (s *SimpleQueueContract) PopN(ctx TransactionContextInterface, queue string, n int) ([]Query, error) {
+	return s.popN(ctx, queue, n, s.back)
+}
+
+// popN remove up to n elements one by one chosen by edge. Queue without suitable elements is not an error
+func (s *SimpleQueueContract) popN(ctx TransactionContextInterface, queue string, n int,
+	edge func(ctx TransactionContextInterface, l *list) (string, error)) ([]Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return nil, err
+	}
+
+	if err = checkBatch(l, n); err != nil {
+		return nil, err
+	}
+
+	res := make([]Query, 0, n)
+
+	for len(res) < n && l.meta.Length > 0 {
+		key, err := edge(ctx, l)
+		if err != nil {
+			if errors.Is(err, ErrEmptyQueue) || (errors.Is(err, ErrWalkLimit) && len(res) > 0) {
+				break
+			}
+
+			return nil, err
+		}
+
+		e, err := l.remove(key)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, Query{key, e.SimpleQueue})
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
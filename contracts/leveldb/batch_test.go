@@ -0,0 +1,98 @@
+// +build unit
+
+package leveldb
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+func (s *Suite) TestBatch() {
+	const queue = "batch"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.PushBackBatch(ctx, queue, `{"n":1}`)
+	s.Error(err, "not an array")
+
+	_, err = s.contract.PushBackBatch(ctx, queue, `[]`)
+	s.Error(err)
+
+	res, err := s.contract.PushBackBatch(ctx, queue, `[{"n":0},{"n":1},null,{"n":3},{"n":4}]`)
+	s.NoError(err)
+	s.Len(res, 5)
+
+	all, err := s.contract.GetAll(ctx, queue)
+	s.NoError(err)
+	s.Equal(res, all)
+
+	for i, q := range res {
+		s.True(now.Equal(q.Object.Time))
+
+		if i > 0 {
+			s.Greater(q.Key, res[i-1].Key)
+		}
+	}
+
+	s.Run("limit", func() {
+		_, err := s.contract.ConfigureQueue(ctx, queue, `{"max_batch":-1}`)
+		s.Error(err)
+
+		_, err = s.contract.ConfigureQueue(ctx, queue, `{"max_batch":3}`)
+		s.NoError(err)
+
+		items := make([]string, 4)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"n":%d}`, i)
+		}
+
+		_, err = s.contract.PushBackBatch(ctx, queue, "["+strings.Join(items, ",")+"]")
+		s.Error(err)
+
+		_, err = s.contract.PopFrontN(ctx, queue, 4)
+		s.Error(err)
+
+		_, err = s.contract.PopN(ctx, queue, 0)
+		s.Error(err)
+
+		// rejected batch doesn't leave anything behind
+		stats, err := s.contract.Stats(ctx, queue)
+		s.NoError(err)
+		s.Equal(len(res), stats.Length)
+	})
+
+	s.Run("PopFrontN", func() {
+		_, err := s.contract.PushBackDelayed(ctx, queue, `{"n":"delayed"}`, "1h")
+		s.NoError(err)
+
+		out, err := s.contract.PopFrontN(ctx, queue, 2)
+		s.NoError(err)
+		s.Equal(res[:2], out)
+	})
+
+	s.Run("PopN", func() {
+		later := s.clientCtx("alice", now.Add(time.Hour))
+
+		out, err := s.contract.PopN(later, queue, 2)
+		s.NoError(err)
+		s.Len(out, 2)
+		s.Equal("delayed", out[0].Object.Context["n"])
+		s.Equal(res[4], out[1])
+
+		// fewer elements left than requested
+		out, err = s.contract.PopN(later, queue, 3)
+		s.NoError(err)
+		s.Equal([]Query{res[3], res[2]}, out)
+
+		out, err = s.contract.PopFrontN(later, queue, 3)
+		s.NoError(err)
+		s.Empty(out)
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
@@ -0,0 +1,92 @@
+package leveldb
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Overflow policies of queue with limited capacity
+const (
+	// OverflowReject fail push with ErrQueueFull. Default policy
+	OverflowReject = "reject"
+
+	// OverflowDropOldest remove head elements till new one fits
+	OverflowDropOldest = "drop_oldest"
+
+	// OverflowDropNew silently discard new element
+	OverflowDropNew = "drop_new"
+)
+
+// ErrQueueFull returned by push operations when queue capacity exceeded with reject policy
+var ErrQueueFull = errors.New("queue is full")
+
+func validOverflow(policy string) bool {
+	switch policy {
+	case "", OverflowReject, OverflowDropOldest, OverflowDropNew:
+		return true
+	}
+
+	return false
+}
+
+// overflow report whether queue exceeds configured capacity
+func (m *QueueMeta) overflow() bool {
+	return (m.Config.MaxLength > 0 && m.Length > m.Config.MaxLength) ||
+		(m.Config.MaxBytes > 0 && m.Bytes > m.Config.MaxBytes)
+}
+
+// fit apply overflow policy after key was added to the queue. Returns false when new element was dropped.
+// Element which doesn't fit into empty queue is rejected regardless of policy
+func (l *list) fit(key string) (bool, error) {
+	if !l.meta.overflow() {
+		return true, nil
+	}
+
+	switch l.meta.Config.Overflow {
+	case OverflowDropNew:
+		if _, err := l.remove(key); err != nil {
+			return false, err
+		}
+
+		return false, nil
+	case OverflowDropOldest:
+		// don't drop anything for element which can't fit anyway
+		if l.meta.Config.MaxBytes > 0 && l.sizes[key] > l.meta.Config.MaxBytes {
+			break
+		}
+
+		for l.meta.overflow() {
+			// in priority mode new element can become the head itself
+			victim := l.meta.Head
+			if victim == key {
+				e, err := l.mustGet(key)
+				if err != nil {
+					return false, err
+				}
+
+				victim = e.Next
+			}
+
+			if victim == "" {
+				break
+			}
+
+			if _, err := l.remove(victim); err != nil {
+				return false, err
+			}
+		}
+
+		if !l.meta.overflow() {
+			return true, nil
+		}
+	}
+
+	limits := fmt.Sprintf("length %d/%d, bytes %d/%d",
+		l.meta.Length, l.meta.Config.MaxLength, l.meta.Bytes, l.meta.Config.MaxBytes)
+
+	if _, err := l.remove(key); err != nil {
+		return false, err
+	}
+
+	return false, fmt.Errorf("queue %q %s: %w", l.meta.Name, limits, ErrQueueFull)
+}
@@ -0,0 +1,93 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+func (s *Suite) TestCapacity() {
+	const queue = "capacity"
+
+	_, err := s.contract.CreateQueue(s.ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.ConfigureQueue(s.ctx, queue, `{"overflow":"drop_all"}`)
+	s.Error(err)
+
+	_, err = s.contract.ConfigureQueue(s.ctx, queue, `{"max_length":-1}`)
+	s.Error(err)
+
+	_, err = s.contract.ConfigureQueue(s.ctx, queue, `{"max_length":2}`)
+	s.NoError(err)
+
+	first, err := s.contract.PushBack(s.ctx, queue, `{"n":1}`)
+	s.NoError(err)
+
+	second, err := s.contract.PushBack(s.ctx, queue, `{"n":2}`)
+	s.NoError(err)
+
+	keys := func() (res []string) {
+		all, err := s.contract.GetAll(s.ctx, queue)
+		s.NoError(err)
+
+		for _, q := range all {
+			res = append(res, q.Key)
+		}
+
+		return res
+	}
+
+	s.Run("reject", func() {
+		_, err := s.contract.PushBack(s.ctx, queue, `{"n":3}`)
+		s.True(errors.Is(err, ErrQueueFull))
+		s.Equal([]string{first.Key, second.Key}, keys())
+
+		stats, err := s.contract.Stats(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(2, stats.Length)
+		s.Equal(s.storedBytes(queue), stats.Bytes)
+	})
+
+	s.Run("drop_new", func() {
+		_, err := s.contract.ConfigureQueue(s.ctx, queue, `{"overflow":"drop_new"}`)
+		s.NoError(err)
+
+		q, err := s.contract.PushBack(s.ctx, queue, `{"n":3}`)
+		s.NoError(err)
+		s.Empty(q.Key)
+		s.Equal(3.0, q.Object.Context["n"])
+		s.Equal([]string{first.Key, second.Key}, keys())
+	})
+
+	s.Run("drop_oldest", func() {
+		_, err := s.contract.ConfigureQueue(s.ctx, queue, `{"overflow":"drop_oldest"}`)
+		s.NoError(err)
+
+		res, err := s.contract.PushBackBatch(s.ctx, queue, `[{"n":3},{"n":4}]`)
+		s.NoError(err)
+		s.Equal([]string{res[0].Key, res[1].Key}, keys())
+	})
+
+	s.Run("bytes", func() {
+		stats, err := s.contract.Stats(s.ctx, queue)
+		s.NoError(err)
+
+		_, err = s.contract.ConfigureQueue(s.ctx, queue, fmt.Sprintf(`{"max_length":0,"max_bytes":%d}`, stats.Bytes))
+		s.NoError(err)
+
+		// bigger element pushes out both old ones
+		big, err := s.contract.PushBack(s.ctx, queue, `{"payload":"`+strings.Repeat("x", stats.Bytes/5)+`"}`)
+		s.NoError(err)
+		s.Equal([]string{big.Key}, keys())
+
+		// element which doesn't fit even into empty queue
+		_, err = s.contract.PushBack(s.ctx, queue, `{"payload":"`+strings.Repeat("x", stats.Bytes)+`"}`)
+		s.True(errors.Is(err, ErrQueueFull))
+		s.Equal([]string{big.Key}, keys())
+	})
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+}
@@ -0,0 +1,333 @@
+package leveldb
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Condition logical operators
+const (
+	ConditionAnd = "AND"
+	ConditionOr  = "OR"
+	ConditionNot = "NOT"
+)
+
+// Condition tree of filters combined with AND, OR and NOT. Leaf condition has empty Op and contains Filter.
+// Zero value matches every element
+type Condition struct {
+	Op     string
+	Args   []Condition
+	Filter Filter
+}
+
+// Empty report whether condition doesn't filter anything
+func (c Condition) Empty() bool {
+	return c.Op == "" && c.Filter.Key == ""
+}
+
+// and combine conditions, empty ones are skipped
+func (c Condition) and(other Condition) Condition {
+	switch {
+	case c.Empty():
+		return other
+	case other.Empty():
+		return c
+	case c.Op == ConditionAnd:
+		c.Args = append(c.Args, other)
+		return c
+	}
+
+	return Condition{Op: ConditionAnd, Args: []Condition{c, other}}
+}
+
+// match evaluate condition against element
+func (c Condition) match(q Query) (bool, error) {
+	switch c.Op {
+	case "":
+		if c.Empty() {
+			return true, nil
+		}
+
+		return c.Filter.matches(q)
+	case ConditionNot:
+		if len(c.Args) != 1 {
+			return false, fmt.Errorf("%s condition should have single argument", ConditionNot)
+		}
+
+		ok, err := c.Args[0].match(q)
+
+		return !ok, err
+	case ConditionAnd, ConditionOr:
+		// AND stops on first false, OR on first true
+		stop := c.Op == ConditionOr
+
+		for _, arg := range c.Args {
+			ok, err := arg.match(q)
+			if err != nil || ok == stop {
+				return ok, err
+			}
+		}
+
+		return !stop, nil
+	}
+
+	return false, fmt.Errorf("unknown condition operator %q", c.Op)
+}
+
+// parseCondition parse filter expression with AND, OR, NOT and parentheses. AND binds tighter than OR.
+// Operators are case insensitive and should be separated by spaces. Values with spaces, parentheses or keywords
+// should be quoted, backslash escapes quote inside: city="New York". Expression without quotes, keywords and
+// grouping is single comparison taken as is: city=New York
+//
+// example: country=BY OR (amount>1000 AND NOT status=done)
+// example: country NOT IN BY,RU AND (email EXISTS OR phone!=null)
+func parseCondition(expr string) (Condition, error) {
+	if plainComparison(expr) {
+		f, err := parseFilter(expr)
+		if err != nil {
+			return Condition{}, fmt.Errorf("filter %q: %w", expr, err)
+		}
+
+		return Condition{Filter: f}, nil
+	}
+
+	tokens, err := tokenizeCondition(expr)
+	if err != nil {
+		return Condition{}, fmt.Errorf("filter %q: %w", expr, err)
+	}
+
+	p := &conditionParser{tokens: tokens}
+
+	c, err := p.or()
+	if err != nil {
+		return Condition{}, fmt.Errorf("filter %q: %w", expr, err)
+	}
+
+	if t, ok := p.peek(); ok {
+		return Condition{}, fmt.Errorf("filter %q: unexpected %q at token %d", expr, t.text, p.pos+1)
+	}
+
+	return c, nil
+}
+
+// plainComparison report whether expression starts with comparison of field and contains neither quotes
+// nor keywords separated by spaces, so it was accepted before conditions were introduced
+func plainComparison(expr string) bool {
+	i := strings.IndexAny(expr, "!=<>\"() \t")
+	if i <= 0 || !strings.ContainsAny(expr[i:i+1], "!=<>") || strings.Contains(expr, `"`) {
+		return false
+	}
+
+	for _, w := range strings.Fields(expr) {
+		if strings.EqualFold(w, ConditionAnd) || strings.EqualFold(w, ConditionOr) || strings.EqualFold(w, ConditionNot) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// conditionToken part of filter expression. Quoted token is never keyword or parenthesis
+type conditionToken struct {
+	text   string
+	quoted bool
+}
+
+// tokenizeCondition split expression by spaces, parentheses are separate tokens. Quoted parts are taken
+// literally and joined with adjacent characters: city="New York" is single token
+func tokenizeCondition(expr string) ([]conditionToken, error) {
+	var (
+		res  []conditionToken
+		cur  strings.Builder
+		open bool
+		tok  conditionToken
+	)
+
+	flush := func() {
+		if open {
+			tok.text = cur.String()
+			res = append(res, tok)
+		}
+
+		cur.Reset()
+		open, tok = false, conditionToken{}
+	}
+
+	for i := 0; i < len(expr); i++ {
+		switch c := expr[i]; c {
+		case '"':
+			i++
+			for ; i < len(expr) && expr[i] != '"'; i++ {
+				if expr[i] == '\\' && i+1 < len(expr) {
+					i++
+				}
+
+				cur.WriteByte(expr[i])
+			}
+
+			if i == len(expr) {
+				return nil, fmt.Errorf("missing closing quote")
+			}
+
+			open, tok.quoted = true, true
+		case ' ', '\t', '\n', '\r':
+			flush()
+		case '(', ')':
+			flush()
+			res = append(res, conditionToken{text: string(c)})
+		default:
+			cur.WriteByte(c)
+			open = true
+		}
+	}
+
+	flush()
+
+	return res, nil
+}
+
+// conditionParser recursive descent parser of filter expression
+type conditionParser struct {
+	tokens []conditionToken
+	pos    int
+}
+
+func (p *conditionParser) peek() (conditionToken, bool) {
+	if p.pos >= len(p.tokens) {
+		return conditionToken{}, false
+	}
+
+	return p.tokens[p.pos], true
+}
+
+// is report whether token is unquoted provided keyword or parenthesis
+func (t conditionToken) is(s string) bool {
+	return !t.quoted && strings.EqualFold(t.text, s)
+}
+
+// keyword consume token when it's provided operator
+func (p *conditionParser) keyword(op string) bool {
+	t, ok := p.peek()
+	if !ok || !t.is(op) {
+		return false
+	}
+
+	p.pos++
+
+	return true
+}
+
+// or := and { OR and }
+func (p *conditionParser) or() (Condition, error) {
+	return p.list(ConditionOr, p.and)
+}
+
+// and := unary { AND unary }
+func (p *conditionParser) and() (Condition, error) {
+	return p.list(ConditionAnd, p.unary)
+}
+
+func (p *conditionParser) list(op string, next func() (Condition, error)) (Condition, error) {
+	c, err := next()
+	if err != nil {
+		return Condition{}, err
+	}
+
+	args := []Condition{c}
+
+	for p.keyword(op) {
+		c, err = next()
+		if err != nil {
+			return Condition{}, err
+		}
+
+		args = append(args, c)
+	}
+
+	if len(args) == 1 {
+		return args[0], nil
+	}
+
+	return Condition{Op: op, Args: args}, nil
+}
+
+// unary := NOT unary | ( or ) | filter
+func (p *conditionParser) unary() (Condition, error) {
+	t, ok := p.peek()
+	if !ok {
+		return Condition{}, fmt.Errorf("unexpected end, expected condition")
+	}
+
+	switch {
+	case p.keyword(ConditionNot):
+		c, err := p.unary()
+		if err != nil {
+			return Condition{}, err
+		}
+
+		return Condition{Op: ConditionNot, Args: []Condition{c}}, nil
+	case t.is("("):
+		p.pos++
+
+		c, err := p.or()
+		if err != nil {
+			return Condition{}, err
+		}
+
+		if t, ok := p.peek(); !ok || !t.is(")") {
+			return Condition{}, fmt.Errorf("missing closing parenthesis at token %d", p.pos+1)
+		}
+
+		p.pos++
+
+		return c, nil
+	case t.is(")"), t.is(ConditionAnd), t.is(ConditionOr):
+		return Condition{}, fmt.Errorf("unexpected %q at token %d, expected condition", t.text, p.pos+1)
+	}
+
+	f, err := p.filter()
+	if err != nil {
+		return Condition{}, err
+	}
+
+	return Condition{Filter: f}, nil
+}
+
+// filter := field EXISTS | field MISSING | field [ NOT ] IN list | comparison
+func (p *conditionParser) filter() (Filter, error) {
+	t := p.tokens[p.pos].text
+	p.pos++
+
+	if strings.ContainsAny(t, "!=<>") {
+		return parseFilter(t)
+	}
+
+	switch {
+	case p.keyword(FilterExists):
+		return Filter{Key: t, Op: FilterExists}, nil
+	case p.keyword(FilterMissing):
+		return Filter{Key: t, Op: FilterMissing}, nil
+	case p.keyword(FilterIn):
+		return p.in(t, FilterIn)
+	case p.keyword(ConditionNot):
+		if !p.keyword(FilterIn) {
+			return Filter{}, fmt.Errorf("expected %s after %s at token %d", FilterIn, ConditionNot, p.pos+1)
+		}
+
+		return p.in(t, FilterNotIn)
+	}
+
+	return parseFilter(t)
+}
+
+// in read comma separated list of values
+func (p *conditionParser) in(key, op string) (Filter, error) {
+	t, ok := p.peek()
+	if !ok || t.is("(") || t.is(")") {
+		return Filter{}, fmt.Errorf("expected list of values after %s at token %d", FilterIn, p.pos+1)
+	}
+
+	p.pos++
+
+	return Filter{Key: key, Value: t.text, Op: op}, nil
+}
@@ -0,0 +1,156 @@
+// +build unit
+
+package leveldb
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCondition(t *testing.T) {
+	sl := SimpleQuery{
+		{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY", "amount": 500.0}}},
+		{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"country": "RU", "amount": 1500.0, "status": "done"}}},
+		{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"country": "UA", "amount": 2000.0}}},
+		{Key: "3", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY", "amount": 3000.0}}},
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"country=BY", "0,3"},
+		{"country=BY OR country=UA", "0,2,3"},
+		{"country=BY AND amount>1000", "3"},
+		// AND binds tighter than OR
+		{"country=UA OR country=BY AND amount>1000", "2,3"},
+		{"(country=UA OR country=BY) AND amount<2500", "0,2"},
+		{"NOT country=BY", "1,2"},
+		{"not (country=BY or status=done)", "2"},
+		{"NOT NOT status=done", "1"},
+		{"amount>1000 AND NOT status=done", "2,3"},
+		{"((amount>=2000))", "2,3"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := parseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCondition() error = %v", err)
+			}
+
+			res, err := sl.Where(c)
+			if err != nil {
+				t.Fatalf("Where() error = %v", err)
+			}
+
+			var keys []string
+			for _, q := range res {
+				keys = append(keys, q.Key)
+			}
+
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("Where() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionMembership(t *testing.T) {
+	sl := SimpleQuery{
+		{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY", "email": "a@b.c"}}},
+		{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"country": "RU", "email": nil}}},
+		{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"country": "UA", "tags": []interface{}{"urgent", "new"}, "scores": []interface{}{1.0, 5.0}}}},
+		{Key: "3", Object: SimpleQueue{Context: map[string]interface{}{"tags": []interface{}{}}}},
+	}
+
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"country IN BY,UA", "0,2"},
+		{"country in RU", "1"},
+		{"country NOT IN BY,UA", "1"},
+		{"country MISSING", "3"},
+		{"email EXISTS", "0,1"},
+		{"email missing", "2,3"},
+		{"email=null", "1"},
+		{"email!=null", "0"},
+		{"email IN null,a@b.c", "0,1"},
+		{"email>a", "0"},
+		// arrays match when any element does, negative operators when none does
+		{"tags=urgent", "2"},
+		{"scores>4", "2"},
+		{"scores<1", ""},
+		{"tags!=urgent", "3"},
+		{"tags NOT IN x,new", "3"},
+		{"tags NOT IN x,y", "2,3"},
+		{"tags.0=urgent", "2"},
+		{"country IN BY,UA AND NOT (email EXISTS)", "2"},
+		{"(country NOT IN RU) OR tags MISSING", "0,1,2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			c, err := parseCondition(tt.expr)
+			if err != nil {
+				t.Fatalf("parseCondition() error = %v", err)
+			}
+
+			res, err := sl.Where(c)
+			if err != nil {
+				t.Fatalf("Where() error = %v", err)
+			}
+
+			var keys []string
+			for _, q := range res {
+				keys = append(keys, q.Key)
+			}
+
+			if got := strings.Join(keys, ","); got != tt.want {
+				t.Errorf("Where() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"", "unexpected end"},
+		{"country=BY AND", "unexpected end"},
+		{"AND country=BY", `unexpected "AND" at token 1`},
+		{"(country=BY", "missing closing parenthesis"},
+		{"(country=BY))", `unexpected ")" at token 4`},
+		{"(country=BY) country=RU", `unexpected "country=RU" at token 4`},
+		{`country="BY`, "missing closing quote"},
+		{"()", `unexpected ")" at token 2`},
+		{"NOT", "unexpected end"},
+		{"country", "wrong filter format"},
+		{"country IN", "expected list of values after IN at token 3"},
+		{"country IN (BY)", "expected list of values after IN at token 3"},
+		{"country NOT BY", "expected IN after NOT at token 3"},
+		{"country EXISTS BY", `unexpected "BY" at token 3`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := parseCondition(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseCondition() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	// type errors are reported on evaluation
+	c, err := parseCondition("b>true OR country=BY")
+	if err != nil {
+		t.Fatalf("parseCondition() error = %v", err)
+	}
+
+	if _, err = (SimpleQuery{{Object: SimpleQueue{Context: map[string]interface{}{"b": true}}}}).Where(c); err == nil {
+		t.Errorf("Where() expected error for bool comparison")
+	}
+}
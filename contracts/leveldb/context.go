@@ -0,0 +1,67 @@
+package leveldb
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+)
+
+// Clock source of current time for transaction.
+// Every endorsing peer should observe the same time, otherwise read/write sets mismatch
+type Clock func(stub shim.ChaincodeStubInterface) (time.Time, error)
+
+// TxClock take time from transaction timestamp which is set by client and equal on all peers
+func TxClock(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	ts, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("get transaction timestamp error: %w", err)
+	}
+
+	return time.Unix(ts.GetSeconds(), int64(ts.GetNanos())).UTC(), nil
+}
+
+// FixedClock always return provided time. Pin time in tests
+func FixedClock(t time.Time) Clock {
+	return func(shim.ChaincodeStubInterface) (time.Time, error) {
+		return t, nil
+	}
+}
+
+// TransactionContextInterface contract transaction context with deterministic clock
+type TransactionContextInterface interface {
+	contractapi.TransactionContextInterface
+
+	// Now return current time of transaction
+	Now() (time.Time, error)
+
+	// Sequence return next number of sequence which starts from zero in every transaction
+	Sequence() int
+}
+
+// TransactionContext default context of SimpleQueueContract
+type TransactionContext struct {
+	contractapi.TransactionContext
+
+	// Clock TxClock when empty
+	Clock Clock
+
+	seq int
+}
+
+// Now return current time of transaction
+func (c *TransactionContext) Now() (time.Time, error) {
+	if c.Clock == nil {
+		return TxClock(c.GetStub())
+	}
+
+	return c.Clock(c.GetStub())
+}
+
+// Sequence return next number of sequence which starts from zero in every transaction
+func (c *TransactionContext) Sequence() int {
+	c.seq++
+
+	return c.seq - 1
+}
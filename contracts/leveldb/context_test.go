@@ -0,0 +1,86 @@
+// +build unit
+
+package leveldb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-contract-api-go/contractapi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxClock(t *testing.T) {
+	stub := shimtest.NewMockStub("clock", new(SimpleChaincode))
+
+	_, err := TxClock(stub)
+	assert.Error(t, err)
+
+	stub.MockTransactionStart("clock")
+	stub.TxTimestamp.Seconds, stub.TxTimestamp.Nanos = 1589702933, 757936000
+
+	ctx := new(TransactionContext)
+	ctx.SetStub(stub)
+
+	now, err := ctx.Now()
+	require.NoError(t, err)
+	assert.Equal(t, mustParse("2020-05-17T11:08:53.757936+03:00").UTC(), now)
+}
+
+func TestChaincode(t *testing.T) {
+	cc, err := contractapi.NewChaincode(new(SimpleQueueContract))
+	require.NoError(t, err)
+
+	stub := shimtest.NewMockStub("chaincode", cc)
+
+	// responses are validated against contract schema, unset optional config isn't shown
+	res := stub.MockInvoke("create", [][]byte{[]byte("CreateQueue"), []byte("orders")})
+	require.EqualValues(t, 200, res.Status, res.Message)
+	assert.NotContains(t, string(res.Payload), "max_receives")
+
+	res = stub.MockInvoke("configure", [][]byte{[]byte("ConfigureQueue"), []byte("orders"), []byte(`{"max_receives":3}`)})
+	require.EqualValues(t, 200, res.Status, res.Message)
+	assert.Contains(t, string(res.Payload), `"max_receives":3`)
+}
+
+func (s *Suite) TestClock() {
+	const queue = "clock"
+
+	pinned := mustParse("2021-05-17T11:08:53.000000001+03:00")
+
+	ctx := &TransactionContext{Clock: FixedClock(pinned)}
+	ctx.SetStub(s.stub)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	res, err := s.contract.PushBack(ctx, queue, `{"country":"PL"}`)
+	s.NoError(err)
+	s.True(pinned.Equal(res.Object.Time))
+
+	t, err := ParseKey(res.Key)
+	s.NoError(err)
+	s.True(pinned.Equal(t))
+
+	s.Run("behind tail", func() {
+		behind := &TransactionContext{Clock: FixedClock(pinned.Add(-time.Hour))}
+		behind.SetStub(s.stub)
+
+		next, err := s.contract.PushBack(behind, queue, `{"country":"PL"}`)
+		s.NoError(err)
+		s.True(pinned.Add(-time.Hour).Equal(next.Object.Time))
+
+		// key still placed after the tail
+		s.Greater(next.Key, res.Key)
+
+		t, err := ParseKey(next.Key)
+		s.NoError(err)
+		s.True(pinned.Add(time.Nanosecond).Equal(t))
+
+		s.NoError(s.contract.Delete(ctx, queue, next.Key))
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
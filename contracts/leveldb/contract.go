@@ -2,10 +2,12 @@ package leveldb
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
-	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 )
 
@@ -13,205 +15,345 @@ import (
 
 // peer chaincode instantiate -n queue -v 0 -c '{"Args":[]}' -C myc
 //
-// peer chaincode invoke -n mycc -c '{"Args":["InitLedger"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["CreateQueue", "default"]}' -C myc
 //
-// peer chaincode invoke -n mycc -c '{"Args":["Get", "1589702933-757936000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["ListQueues"]}' -C myc
 //
-// peer chaincode invoke -n mycc -c '{"Args":["Update", "1589702933-757936000", "{\"country\":\"RU\"}"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["DeleteQueue", "default"]}' -C myc
 //
-// peer chaincode invoke -n mycc -c '{"Args":["Delete", "1589702933-757936000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Stats", "default"]}' -C myc
 //
-// peer chaincode invoke -n mycc -c '{"Args":["GetAll"]}' -C myc
+// stop producers during incident, then return queue into active state
+// peer chaincode invoke -n mycc -c '{"Args":["Pause", "default"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Resume", "default"]}' -C myc
 //
-// peer chaincode invoke -n mycc -c '{"Args":["GetRange", "0", "1558080533-00000000"]}' -C myc
-// peer chaincode invoke -n mycc -c '{"Args":["GetRange", "0", "1305619733-758090001"]}' -C myc
+// keep up to 1000 elements, drop the oldest ones when the queue is full
+// peer chaincode invoke -n mycc -c '{"Args":["ConfigureQueue", "default", "{\"max_length\":1000,\"overflow\":\"drop_oldest\"}"]}' -C myc
+//
+// move elements delivered 5 times into dead-letter queue default.dlq
+// peer chaincode invoke -n mycc -c '{"Args":["ConfigureQueue", "default", "{\"max_receives\":5}"]}' -C myc
+//
+// peer chaincode invoke -n mycc -c '{"Args":["InitLedger", "default"]}' -C myc
+//
+// peer chaincode invoke -n mycc -c '{"Args":["Get", "default", "v2-001589702933-757936000-00000000-0000"]}' -C myc
+//
+// peer chaincode invoke -n mycc -c '{"Args":["Update", "default", "v2-001589702933-757936000-00000000-0000", "{\"country\":\"RU\"}"]}' -C myc
+//
+// peer chaincode invoke -n mycc -c '{"Args":["Delete", "default", "v2-001589702933-757936000-00000000-0000"]}' -C myc
+//
+// peer chaincode invoke -n mycc -c '{"Args":["GetAll", "default"]}' -C myc
+//
+// peer chaincode invoke -n mycc -c '{"Args":["GetRange", "default", "0", "v2-001558080533"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["GetRange", "default", "0", "v2-001305619733-758090001-00000000-0000"]}' -C myc
 //
 //
 //  ==== START QUERY ====
-// peer chaincode invoke -n mycc -c '{"Args":["Query", "from=0&to=1558080533-00000000&sort=country&filter=country=BY"]}' -C myc
-// peer chaincode invoke -n mycc -c '{"Args":["Query", "sort=country"]}' -C myc
-// peer chaincode invoke -n mycc -c '{"Args":["Query", "filter=country=RU2"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "from=0&to=v2-001558080533&sort=country&filter=country=BY"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "sort=country"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "filter=country=RU2"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "filter=num>=1000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "filter=country=BY OR (num>1000 AND NOT country=RU)"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "filter=address.country=BY&sort=items.0.sku"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Query", "default", "filter=country IN BY,RU AND (email MISSING OR email=null)"]}' -C myc
 //  ==== END QUERY ====
 //
+// page through the queue by 100 elements, pass returned bookmark till it's empty
+// peer chaincode query -n mycc -c '{"Args":["GetRangeWithPagination", "default", "", "", "100", ""]}' -C myc
+// peer chaincode query -n mycc -c '{"Args":["QueryWithPagination", "default", "filter=country=BY", "100", ""]}' -C myc
+//
 // push empty string
-// peer chaincode invoke -n mycc -c '{"Args":["PushBack", ""]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PushBack", "default", ""]}' -C myc
 //
 // push empty json string
-// peer chaincode invoke -n mycc -c '{"Args":["PushBack", "{}"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PushBack", "default", "{}"]}' -C myc
 //
 // push extra json context. Take look on character escaping
-// peer chaincode invoke -n mycc -c '{"Args":["PushBack", "{\"country\":\"BY\"}"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PushBack", "default", "{\"country\":\"BY\"}"]}' -C myc
+//
+// switch empty queue into priority mode, push element with priority 10 and raise it later
+// peer chaincode invoke -n mycc -c '{"Args":["ConfigureQueue", "default", "{\"mode\":\"priority\"}"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PushBackPriority", "default", "{\"country\":\"BY\"}", "10"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["ChangePriority", "default", "p-9989-v2-001589702933-757936000-9f86d081-0000", "100"]}' -C myc
+//
+// push element once, retry with the same deduplication ID returns original element
+// peer chaincode invoke -n mycc -c '{"Args":["PushBackIdempotent", "default", "order-42", "{\"country\":\"BY\"}"]}' -C myc
+//
+// push element visible since provided time or after delay
+// peer chaincode invoke -n mycc -c '{"Args":["PushBackAt", "default", "{\"country\":\"BY\"}", "2021-05-17T11:08:53+03:00"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PushBackDelayed", "default", "{\"country\":\"BY\"}", "5m"]}' -C myc
+//
+// push element which lives 24 hours, restart lifetime of element, delete expired elements
+// peer chaincode invoke -n mycc -c '{"Args":["PushBackTTL", "default", "{\"country\":\"BY\"}", "24h"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["UpdateTTL", "default", "v2-001589702933-757936000-00000000-0000", "", "24h"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PurgeExpired", "default", "100"]}' -C myc
+//
+// push element before the head
+// peer chaincode invoke -n mycc -c '{"Args":["PushFront", "default", "{\"country\":\"BY\"}"]}' -C myc
 //
 // access first element
-// peer chaincode invoke -n mycc -c '{"Args":["Front"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Front", "default"]}' -C myc
 //
 // access last element
-// peer chaincode invoke -n mycc -c '{"Args":["Back"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Back", "default"]}' -C myc
 //
-// get last element and remove them last element
-// peer chaincode invoke -n mycc -c '{"Args":["Pop"]}' -C myc
+// access up to 10 first or last elements without removing them
+// peer chaincode invoke -n mycc -c '{"Args":["PeekFront", "default", "10"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PeekBack", "default", "10"]}' -C myc
 //
 // get last element and remove them last element
-// peer chaincode invoke -n mycc -c '{"Args":["Swap","1305619733-758090000", "1337242133-758089000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PopBack", "default"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Pop", "default"]}' -C myc
+//
+// get first element and remove it from queue
+// peer chaincode invoke -n mycc -c '{"Args":["PopFront", "default"]}' -C myc
+//
+// push several elements, get and remove up to 10 first or last elements
+// peer chaincode invoke -n mycc -c '{"Args":["PushBackBatch", "default", "[{\"country\":\"BY\"},{\"country\":\"RU\"}]"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PopFrontN", "default", "10"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["PopN", "default", "10"]}' -C myc
+//
+// swap context of 2 elements
+// peer chaincode invoke -n mycc -c '{"Args":["Swap", "default", "v2-001305619733-758090000-00000000-0000", "v2-001337242133-758089000-00000000-0000"]}' -C myc
+//
+// reverse or rotate by one position contexts of elements from provided key till the end
+// peer chaincode invoke -n mycc -c '{"Args":["Reverse", "default", "v2-001305619733-758090000-00000000-0000", ""]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Rotate", "default", "v2-001305619733-758090000-00000000-0000", "", "1"]}' -C myc
+//
+// move element before or after another one, or to position counted from the head
+// peer chaincode invoke -n mycc -c '{"Args":["MoveBefore", "default", "v2-001305619733-758090000-00000000-0000", "v2-001589702933-757936000-00000000-0000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["MoveAfter", "default", "v2-001305619733-758090000-00000000-0000", "v2-001589702933-757936000-00000000-0000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["MoveTo", "default", "v2-001305619733-758090000-00000000-0000", "0"]}' -C myc
+//
+// read the queue by consumer group without deleting elements, then commit last processed key
+// peer chaincode invoke -n mycc -c '{"Args":["CreateGroup", "default", "billing"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["ReadNext", "default", "billing", "10"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Commit", "default", "billing", "v2-001589702933-757936000-00000000-0000"]}' -C myc
+//
+// lease up to 10 elements for 30 seconds, then confirm or release them
+// peer chaincode invoke -n mycc -c '{"Args":["Receive", "default", "10", "30s"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Ack", "default", "v2-001589702933-757936000-00000000-0000", "9f86d081-0000"]}' -C myc
+// peer chaincode invoke -n mycc -c '{"Args":["Nack", "default", "v2-001589702933-757936000-00000000-0000", "9f86d081-0000"]}' -C myc
+//
+// push dead-lettered elements back into the queue
+// peer chaincode invoke -n mycc -c '{"Args":["RedriveDeadLetters", "default", "100"]}' -C myc
+//
+// move up to 100 elements of flat queue into default queue
+// peer chaincode invoke -n mycc -c '{"Args":["MigrateKeys","100"]}' -C myc
 //
 
+// ErrEmptyQueue returned by operations which access edge elements of queue which has no elements
+var ErrEmptyQueue = errors.New("queue is empty")
 
 // WithUniqueProperty smart contract /codechain/ which internally don't handle unique entity property
 type SimpleQueueContract struct {
 	contractapi.Contract
 }
 
-// just example using composite key, for us this is not suitable as we not use search via prefix.
-func (s *SimpleQueueContract) compositeKey(stub shim.ChaincodeStubInterface) (string, error) {
-	t := time.Now()
-	ut := fmt.Sprintf("%d", t.Unix())
-	ns := fmt.Sprintf("%d", t.Nanosecond())
+// GetTransactionContextHandler return TransactionContext when other handler not provided
+func (s *SimpleQueueContract) GetTransactionContextHandler() contractapi.SettableTransactionContextInterface {
+	if s.TransactionContextHandler == nil {
+		return new(TransactionContext)
+	}
+
+	return s.TransactionContextHandler
+}
+
+// InitLedger adds a base set of assets to the empty queue. Queue created when not exists
+func (s *SimpleQueueContract) InitLedger(ctx TransactionContextInterface, queue string) ([]Query, error) {
+	fixtures := []SimpleQueue{
+		// v2-001589702933-757936000-00000000-0000
+		{Time: mustParse("2020-05-17T11:08:53.757936+03:00"), Context: map[string]interface{}{"country": "BY"}},
+		// v2-001558080533-758077000-00000000-0000
+		{Time: mustParse("2019-05-17T11:08:53.758077+03:00"), Context: map[string]interface{}{"country": "RU"}},
+		// v2-001526544533-758079000-00000000-0000
+		{Time: mustParse("2018-05-17T11:08:53.758079+03:00"), Context: map[string]interface{}{"country": "UA"}},
+		// v2-001495008533-758081000-00000000-0000
+		{Time: mustParse("2017-05-17T11:08:53.758081+03:00"), Context: map[string]interface{}{"country": "BY"}},
+		// v2-001463472533-758082000-00000000-0000
+		{Time: mustParse("2016-05-17T11:08:53.758082+03:00"), Context: map[string]interface{}{"country": "BY", "num": 10_000_000}},
+		// v2-001431850133-758084000-00000000-0000
+		{Time: mustParse("2015-05-17T11:08:53.758084+03:00"), Context: map[string]interface{}{"country": "UA"}},
+		// v2-001400314133-758086000-00000000-0000
+		{Time: mustParse("2014-05-17T11:08:53.758086+03:00"), Context: map[string]interface{}{"country": "BY"}},
+		// v2-001368778133-758087000-00000000-0000
+		{Time: mustParse("2013-05-17T11:08:53.758087+03:00"), Context: map[string]interface{}{"country": "RU2"}},
+		// v2-001337242133-758089000-00000000-0000
+		{Time: mustParse("2012-05-17T11:08:53.758089+03:00"), Context: map[string]interface{}{"country": "BY"}},
+		// v2-001305619733-758090000-00000000-0000
+		{Time: mustParse("2011-05-17T11:08:53.75809+03:00"), Context: map[string]interface{}{"country": "UA"}},
+	}
+
+	l, err := openQueue(ctx, queue)
+	if errors.Is(err, ErrQueueNotFound) {
+		l, err = createList(ctx.GetStub(), queue)
+	}
 
-	key, err := stub.CreateCompositeKey(ut, []string{ns})
 	if err != nil {
-		return "", fmt.Errorf("create composite key [%s-%s] error: %w", ut, ns, err)
+		return nil, err
 	}
 
-	return key, nil
-}
+	if err = l.allow(accessPush); err != nil {
+		return nil, err
+	}
 
-// InitLedger adds a base set of assets to the ledger
-func (s *SimpleQueueContract) InitLedger(ctx contractapi.TransactionContextInterface) ([]Query, error) {
-	list := []SimpleQueue{
-		// 1589702933-757936000
-		{mustParse("2020-05-17T11:08:53.757936+03:00"), map[string]interface{}{"country": "BY"}},
-		// 1558080533-758077000
-		{mustParse("2019-05-17T11:08:53.758077+03:00"), map[string]interface{}{"country": "RU"}},
-		// 1526544533-758079000
-		{mustParse("2018-05-17T11:08:53.758079+03:00"), map[string]interface{}{"country": "UA"}},
-		// 1495008533-758081000
-		{mustParse("2017-05-17T11:08:53.758081+03:00"), map[string]interface{}{"country": "BY"}},
-		// 1463472533-758082000
-		{mustParse("2016-05-17T11:08:53.758082+03:00"), map[string]interface{}{"country": "BY", "num": 10_000_000}},
-		// 1431850133-758084000
-		{mustParse("2015-05-17T11:08:53.758084+03:00"), map[string]interface{}{"country": "UA"}},
-		// 1400314133-758086000
-		{mustParse("2014-05-17T11:08:53.758086+03:00"), map[string]interface{}{"country": "BY"}},
-		// 1368778133-758087000
-		{mustParse("2013-05-17T11:08:53.758087+03:00"), map[string]interface{}{"country": "RU2"}},
-		// 1337242133-758089000
-		{mustParse("2012-05-17T11:08:53.758089+03:00"), map[string]interface{}{"country": "BY"}},
-		// 1305619733-758090000
-		{mustParse("2011-05-17T11:08:53.75809+03:00"), map[string]interface{}{"country": "UA"}},
-	}
-
-	res := make([]Query, len(list))
-
-	for i := range list {
-		data, err := json.Marshal(list[i])
-		if err != nil {
-			return nil, fmt.Errorf("marshal data error: %w", err)
-		}
+	if l.meta.Length > 0 {
+		return nil, fmt.Errorf("queue %q already initialized: contains %d elements", queue, l.meta.Length)
+	}
+
+	// queue order should follow key order
+	sort.Slice(fixtures, func(i, j int) bool {
+		return fixtures[i].Time.Before(fixtures[j].Time)
+	})
+
+	res := make([]Query, len(fixtures))
 
-		res[i].Object = list[i]
-		res[i].Key = TimedKey(list[i].Time)
+	for i := range fixtures {
+		res[i].Object = fixtures[i]
+		res[i].Key = TimedKeyV2(fixtures[i].Time, fixtureSuffix)
 
-		if err := ctx.GetStub().PutState(res[i].Key, data); err != nil {
+		if err = l.pushBack(res[i].Key, &entry{SimpleQueue: fixtures[i]}); err != nil {
 			return nil, fmt.Errorf("write state error: %w", err)
 		}
 	}
 
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
 	return res, nil
 }
 
-// Get extract existing queue element by  it's key
-func (s *SimpleQueueContract) Get(ctx contractapi.TransactionContextInterface, key string) (*Query, error) {
-	v, err := ctx.GetStub().GetState(key)
+// Get extract existing queue element by  it's key. Expired element is treated as not existing
+func (s *SimpleQueueContract) Get(ctx TransactionContextInterface, queue, key string) (*Query, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := l.get(key)
 	switch {
 	case err != nil:
 		return nil, fmt.Errorf("error extracting object with provided key: %w", err)
-	case v == nil:
+	case e == nil:
 		return nil, fmt.Errorf("asset with key %s not exists", key)
+	case e.Expired(now):
+		return nil, fmt.Errorf("asset with key %s not exists: expired at %s", key, e.Expiry.At.Format(time.RFC3339Nano))
 	}
 
-	q := &Query{Key: key}
-
-	if err = json.Unmarshal(v, &q.Object); err != nil {
-		return nil, fmt.Errorf("unmarshal old asset: %w", err)
-	}
-
-	return q, nil
+	return &Query{key, e.SimpleQueue}, nil
 }
 
 // Update existing queue element by  it's key
 // @js - expect correct JSON valid extra context data. Can be empty
-func (s *SimpleQueueContract) Update(ctx contractapi.TransactionContextInterface, key string, js string) (*Query, error) {
-	old, err := s.Get(ctx, key)
+func (s *SimpleQueueContract) Update(ctx TransactionContextInterface, queue, key string, js string) (*Query, error) {
+	return s.update(ctx, queue, key, js, nil)
+}
+
+// update merge extra context into existing element and apply optional change of element
+func (s *SimpleQueueContract) update(ctx TransactionContextInterface, queue, key, js string, change func(item *SimpleQueue)) (*Query, error) {
+	l, err := openQueue(ctx, queue)
 	if err != nil {
 		return nil, err
 	}
 
+	if err = l.allow(accessEdit); err != nil {
+		return nil, err
+	}
+
+	old, err := l.get(key)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("error extracting object with provided key: %w", err)
+	case old == nil:
+		return nil, fmt.Errorf("asset with key %s not exists", key)
+	}
+
 	if len(js) > 0 {
-		if err = json.Unmarshal([]byte(js), &old.Object.Context); err != nil {
+		if err = json.Unmarshal([]byte(js), &old.Context); err != nil {
 			return nil, fmt.Errorf("unmarshal extra context data: %w", err)
 		}
 	}
 
-	blob, err := json.Marshal(old.Object)
-	if err != nil {
-		return nil, fmt.Errorf("marshal updated object: %w", err)
+	if change != nil {
+		change(&old.SimpleQueue)
 	}
 
-	if err = ctx.GetStub().PutState(old.Key, blob); err != nil {
+	if err = l.put(key, old); err != nil {
 		return nil, fmt.Errorf("save state: %w", err)
 	}
 
-	return old, nil
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return &Query{key, old.SimpleQueue}, nil
 }
 
 // Delete asset by key
-func (s *SimpleQueueContract) Delete(ctx contractapi.TransactionContextInterface, key string) error {
-	_, err := s.Get(ctx, key)
+func (s *SimpleQueueContract) Delete(ctx TransactionContextInterface, queue, key string) error {
+	l, err := openQueue(ctx, queue)
 	if err != nil {
 		return err
 	}
 
-	if err = ctx.GetStub().DelState(key); err != nil {
-		return fmt.Errorf("delete object: %w", err)
+	if err = l.allow(accessPop); err != nil {
+		return err
 	}
 
-	return nil
+	if _, err = l.remove(key); err != nil {
+		return err
+	}
+
+	return l.save()
 }
 
 // GetAll list of queue
 // very expensive operation which read all queue till last element
-func (s *SimpleQueueContract) GetAll(ctx contractapi.TransactionContextInterface) (res []Query, err error) {
-	return s.GetRange(ctx, "", TimedKey(time.Now()))
+func (s *SimpleQueueContract) GetAll(ctx TransactionContextInterface, queue string) (res []Query, err error) {
+	return s.GetRange(ctx, queue, "", "")
 }
 
 // GetRange get range [from, to)
-func (s *SimpleQueueContract) GetRange(ctx contractapi.TransactionContextInterface, from, to string) (res SimpleQuery, err error) {
+// Composite keys can't be used in range extraction, so queue scanned from the beginning till @to
+// Expired elements are excluded
+func (s *SimpleQueueContract) GetRange(ctx TransactionContextInterface, queue, from, to string) (res SimpleQuery, err error) {
 	if to == "" {
-		to = TimedKey(time.Now())
+		to = lastKey
 	}
 
 	// support backport extraction
-	if to < from{
+	if to < from {
 		from, to = to, from
 	}
 
-	itr, err := ctx.GetStub().GetStateByRange(from, to)
+	now, err := ctx.Now()
 	if err != nil {
-		return nil, fmt.Errorf("can't get range state")
+		return nil, err
 	}
 
-	for itr.HasNext() {
-		i, err := itr.Next()
-		if err != nil {
-			return nil, fmt.Errorf("next result error: %w", err)
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	err = l.scan(func(key string, e *entry) (bool, error) {
+		if key >= to {
+			return false, nil
 		}
 
-		obj := SimpleQueue{}
-		if err = json.Unmarshal(i.Value, &obj); err != nil {
-			return nil, fmt.Errorf("unmarshal error: %w", err)
+		if key >= from && !e.Expired(now) {
+			res = append(res, Query{key, e.SimpleQueue})
 		}
 
-		res = append(res, Query{i.Key, obj})
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return res, nil
@@ -220,28 +362,54 @@ func (s *SimpleQueueContract) GetRange(ctx contractapi.TransactionContextInterfa
 // Query extract list of element using operation query
 // Supported operations uses url query syntax and support followed arguments:
 // @from - select from which key should performed result extraction. Empty uses as from beggining
-// @to - select to which key should be performed range extraction. (provided value excluded). Empty till NOW
-// @Filter - extra context filtering result. Uses = separator and support only equation.
+// @to - select to which key should be performed range extraction. (provided value excluded). Empty till the end
+// @Filter - extra context filtering result. Supports operators = != > >= < <=. Numbers compared as numbers,
+// RFC3339 times as times, other strings lexicographically. Elements without the field are excluded unless MISSING.
 //  example: Filter=country=RU
+//  example: Filter=amount>1000
+// IN and NOT IN match comma separated list, EXISTS and MISSING check presence of the field, null matches JSON null.
+// Array field matches when any of its elements does, != and NOT IN when none does
+//  example: Filter=country IN BY,RU
+//  example: Filter=email MISSING OR email=null
+// Comparisons can be combined with AND, OR, NOT and parentheses. Several filters are combined with AND
+//  example: Filter=country=BY OR (amount>1000 AND NOT status=done)
+// Field of filter and sort can be a dot separated path to nested value, array elements are addressed by index
+//  example: Filter=address.country=BY
+//  example: Filter=items.0.sku=A-1
 // @Sort - order result with some provided context field, if field not exists result will be in the end of slice
 //
 // ascending example: Sort=country
 // descending example: Sort=-country
 //
 // Sort require all context data provided with type consistency
-func (s *SimpleQueueContract) Query(ctx contractapi.TransactionContextInterface, operation string) (res []Query, err error) {
+// Scheduled elements which time didn't come are excluded
+func (s *SimpleQueueContract) Query(ctx TransactionContextInterface, queue, operation string) (res []Query, err error) {
 	op, err := ParseOperation(operation)
 	if err != nil {
 		return nil, fmt.Errorf("read operation parameter error: %w", err)
 	}
 
-	v, err := s.GetRange(ctx, op.Selector.From, op.Selector.To)
+	v, err := s.GetRange(ctx, queue, op.Selector.From, op.Selector.To)
 	if err != nil {
 		return nil, fmt.Errorf("extract range error: %w", err)
 	}
 
-	if op.Filter.Key != "" {
-		v, err = v.Filter(op.Filter)
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	return op.apply(v, now)
+}
+
+// apply exclude scheduled elements, then filter and sort the rest
+func (op *operation) apply(v SimpleQuery, now time.Time) (SimpleQuery, error) {
+	var err error
+
+	v = v.Visible(now)
+
+	if !op.Filter.Empty() {
+		v, err = v.Where(op.Filter)
 		if err != nil {
 			return nil, fmt.Errorf("filtering error: %w", err)
 		}
@@ -256,143 +424,434 @@ func (s *SimpleQueueContract) Query(ctx contractapi.TransactionContextInterface,
 
 // PushBack create new queue element and put it to the end of queue
 // @js - expect correct JSON valid extra context data. Can be empty
-func (s *SimpleQueueContract) PushBack(ctx contractapi.TransactionContextInterface, js string) (*Query, error) {
-	item := NewSimpleQueue()
+func (s *SimpleQueueContract) PushBack(ctx TransactionContextInterface, queue, js string) (*Query, error) {
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pushItem(ctx, queue, item)
+}
+
+// newItem create element at transaction time with provided extra context
+func newItem(ctx TransactionContextInterface, js string) (SimpleQueue, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return SimpleQueue{}, err
+	}
+
+	item := NewSimpleQueue(now)
 
 	if len(js) > 0 {
 		if err := json.Unmarshal([]byte(js), &item.Context); err != nil {
-			return nil, fmt.Errorf("parameter has bad JSON format")
+			return SimpleQueue{}, fmt.Errorf("parameter has bad JSON format")
 		}
 	}
 
-	// test composition key how it uses
-	fmt.Println(s.compositeKey(ctx.GetStub()))
+	return item, nil
+}
 
-	out := &Query{Key: TimedKey(item.Time), Object: item}
+// pushItem append prepared element to the queue
+func (s *SimpleQueueContract) pushItem(ctx TransactionContextInterface, queue string, item SimpleQueue) (*Query, error) {
+	res, err := s.pushItems(ctx, queue, []SimpleQueue{item})
+	if err != nil {
+		return nil, err
+	}
+
+	return &res[0], nil
+}
 
-	blob, err := json.Marshal(&item)
+// pushItems append prepared elements to the queue in provided order
+func (s *SimpleQueueContract) pushItems(ctx TransactionContextInterface, queue string, items []SimpleQueue) ([]Query, error) {
+	l, err := openQueue(ctx, queue)
 	if err != nil {
-		return nil, fmt.Errorf("marhaling error :%w", err)
+		return nil, err
+	}
+
+	if err = l.allow(accessPush); err != nil {
+		return nil, err
+	}
+
+	res := make([]Query, 0, len(items))
+
+	for _, item := range items {
+		out, err := push(ctx, l, item)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, *out)
 	}
 
-	if err = ctx.GetStub().PutState(out.Key, blob); err != nil {
-		return nil, fmt.Errorf("")
+	if err = l.save(); err != nil {
+		return nil, err
 	}
 
-	return out, nil
+	return res, nil
 }
 
-// Front extract first element of queue
-// very expensive operation which read all queue till last element
-func (s *SimpleQueueContract) Front(ctx contractapi.TransactionContextInterface) (*Query, error) {
-	itr, err := ctx.GetStub().GetStateByRange("", TimedKey(time.Now()))
+// push append element to the queue under new key.
+// In priority mode element placed after all elements with the same or higher priority.
+// Caller should save the list
+func push(ctx TransactionContextInterface, l *list, item SimpleQueue) (*Query, error) {
+	return admit(ctx, l, item, func(e *entry) (string, error) {
+		if l.meta.Config.Mode == QueueModePriority {
+			return insertByPriority(ctx, l, e)
+		}
+
+		key, err := newKey(ctx, l.meta.Tail, e.Time)
+		if err != nil {
+			return "", err
+		}
+
+		return key, l.pushBack(key, e)
+	})
+}
+
+// admit add element to the queue with provided insert function. Element without expiry gets default TTL of the queue,
+// element scheduled for later is parked. Capacity limits of the queue are applied, dropped element returned
+// with empty key
+func admit(ctx TransactionContextInterface, l *list, item SimpleQueue, insert func(e *entry) (string, error)) (*Query, error) {
+	if item.Expiry == nil && l.meta.Config.TTL != "" {
+		expiry, err := newExpiry(ctx, l.meta.Config.TTL)
+		if err != nil {
+			return nil, err
+		}
+
+		item.Expiry = expiry
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &entry{SimpleQueue: item}
+
+	key, err := insert(e)
 	if err != nil {
-		return nil, fmt.Errorf("can't get range state")
+		return nil, err
 	}
 
-	if !itr.HasNext() {
-		return nil, nil
+	if err = l.hide(key, e, now); err != nil {
+		return nil, err
 	}
 
-	i, err := itr.Next()
+	kept, err := l.fit(key)
 	if err != nil {
-		return nil, fmt.Errorf("next result error: %w", err)
+		return nil, err
 	}
 
-	res := SimpleQueue{}
-	if err = json.Unmarshal(i.Value, &res); err != nil {
-		return nil, fmt.Errorf("unmarshal error: %w", err)
+	if !kept {
+		key = ""
 	}
 
-	return &Query{i.Key, res}, nil
+	return &Query{Key: key, Object: item}, nil
 }
 
-// Back extract last element of queue
-// very expensive operation which read all queue till last element
-func (s *SimpleQueueContract) Back(ctx contractapi.TransactionContextInterface) (*Query, error) {
-	itr, err := ctx.GetStub().GetStateByRange("", TimedKey(time.Now()))
+// Front extract first visible element of queue or ErrEmptyQueue. Scheduled, expired and leased elements are skipped
+func (s *SimpleQueueContract) Front(ctx TransactionContextInterface, queue string) (*Query, error) {
+	l, err := openQueue(ctx, queue)
 	if err != nil {
-		return nil, fmt.Errorf("can't get range state")
+		return nil, err
 	}
 
-	if !itr.HasNext() {
-		return nil, nil
+	key, err := s.front(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.edge(l, key)
+}
+
+// front return key of first element which is visible, not expired and not leased at transaction time
+func (s *SimpleQueueContract) front(ctx TransactionContextInterface, l *list) (string, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return "", err
 	}
 
-	for {
-		i, err := itr.Next()
+	for key, skipped := l.meta.Head, 0; key != ""; skipped++ {
+		if err = checkWalk(l, skipped); err != nil {
+			return "", err
+		}
+
+		e, err := l.mustGet(key)
 		if err != nil {
-			return nil, fmt.Errorf("next result error: %w", err)
+			return "", err
 		}
 
-		// we interested only in last element
-		if itr.HasNext() {
-			continue
+		if e.Visible(now) && !e.Expired(now) && !e.Lease.Active(now) {
+			return key, nil
 		}
 
-		res := SimpleQueue{}
-		if err = json.Unmarshal(i.Value, &res); err != nil {
-			return nil, fmt.Errorf("unmarshal error: %w", err)
+		key = e.Next
+	}
+
+	if l.meta.Length > 0 {
+		return "", fmt.Errorf("all %d elements are scheduled, expired or leased: %w", l.meta.Length, ErrEmptyQueue)
+	}
+
+	return "", nil
+}
+
+// Back extract last element of queue or ErrEmptyQueue. Scheduled, expired and leased elements are skipped
+func (s *SimpleQueueContract) Back(ctx TransactionContextInterface, queue string) (*Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.back(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.edge(l, key)
+}
+
+// back return key of last element which is not expired and not leased at transaction time
+func (s *SimpleQueueContract) back(ctx TransactionContextInterface, l *list) (string, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return "", err
+	}
+
+	for key, skipped := l.meta.Tail, 0; key != ""; skipped++ {
+		if err = checkWalk(l, skipped); err != nil {
+			return "", err
+		}
+
+		e, err := l.mustGet(key)
+		if err != nil {
+			return "", err
+		}
+
+		if !e.Expired(now) && !e.Lease.Active(now) {
+			return key, nil
 		}
 
-		return &Query{i.Key, res}, nil
+		key = e.Prev
 	}
+
+	if l.meta.Length > 0 {
+		return "", fmt.Errorf("all %d elements are expired or leased: %w", l.meta.Length, ErrEmptyQueue)
+	}
+
+	return "", nil
 }
 
-// Pop extract and remove last element of queue
-// very expensive operation which read all queue till last element
-func (s *SimpleQueueContract) Pop(ctx contractapi.TransactionContextInterface) (*Query, error) {
-	q, err := s.Back(ctx)
+// Pop extract and remove last element of queue. Same as PopBack
+func (s *SimpleQueueContract) Pop(ctx TransactionContextInterface, queue string) (*Query, error) {
+	return s.PopBack(ctx, queue)
+}
+
+// PopFront extract and remove first visible element of queue. Scheduled, expired and leased elements are skipped
+func (s *SimpleQueueContract) PopFront(ctx TransactionContextInterface, queue string) (*Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return nil, err
+	}
+
+	key, err := s.front(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pop(l, key)
+}
+
+// edge read head or tail element
+func (s *SimpleQueueContract) edge(l *list, key string) (*Query, error) {
+	if key == "" {
+		return nil, l.errEmpty()
+	}
+
+	e, err := l.mustGet(key)
 	if err != nil {
 		return nil, err
 	}
 
-	if err = ctx.GetStub().DelState(q.Key); err != nil {
-		return nil, fmt.Errorf("delete state key %q error: %w", q.Key, err)
+	return &Query{key, e.SimpleQueue}, nil
+}
+
+// pop remove head or tail element
+func (s *SimpleQueueContract) pop(l *list, key string) (*Query, error) {
+	if key == "" {
+		return nil, l.errEmpty()
+	}
+
+	e, err := l.remove(key)
+	if err != nil {
+		return nil, fmt.Errorf("delete state key %q error: %w", key, err)
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
 	}
 
-	return q, nil
+	return &Query{key, e.SimpleQueue}, nil
 }
 
 // Swap replace between 2 elements their context
-// Swap performed only with context data
-func (s *SimpleQueueContract) Swap(ctx contractapi.TransactionContextInterface, a, b string) (bool, error) {
+// Swap performed only with context data: keys and therefore queue links stay in place, created_at is not exchanged.
+// Use MoveBefore, MoveAfter or MoveTo to change position of element
+func (s *SimpleQueueContract) Swap(ctx TransactionContextInterface, queue, a, b string) (bool, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return false, err
+	}
+
+	if err = l.allow(accessEdit); err != nil {
+		return false, err
+	}
+
 	if a == b {
 		return true, nil
 	}
 
-	first, err := s.Get(ctx, a)
-	if err != nil {
+	first, err := l.get(a)
+	switch {
+	case err != nil:
 		return false, fmt.Errorf("first element %q exstraction error: %w", a, err)
+	case first == nil:
+		return false, fmt.Errorf("first element %q extraction error: asset with key %s not exists", a, a)
 	}
 
-	second, err := s.Get(ctx, b)
-	if err != nil {
+	second, err := l.get(b)
+	switch {
+	case err != nil:
 		return false, fmt.Errorf("second element %q extraction error: %w", b, err)
+	case second == nil:
+		return false, fmt.Errorf("second element %q extraction error: asset with key %s not exists", b, b)
 	}
 
-	first.Object.Time, second.Object.Time = second.Object.Time, first.Object.Time
+	first.Context, second.Context = second.Context, first.Context
 
-	firstBlob, err := json.Marshal(&first.Object)
+	if err = l.put(b, second); err != nil {
+		return false, fmt.Errorf("key %q put context error: %w", b, err)
+	}
+
+	// is that ROLLBACK previous operation
+	if err = l.put(a, first); err != nil {
+		return false, fmt.Errorf("key %q put first context error: %w", a, err)
+	}
+
+	if err = l.save(); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// MigrateKeys move up to limit elements of flat queue which was used before named queues into DefaultQueue.
+// Legacy TimedKey keys rewritten to TimedKeyV2 format, elements keep their order.
+// Elements written before queue metadata was introduced have neither links nor metadata, they are older than
+// linked ones and migrated first in order of their time. Every call reads only up to limit of such elements.
+// Returns amount of migrated elements, call it until zero returned. Till then missing or empty DefaultQueue
+// errors point to MigrateKeys.
+func (s *SimpleQueueContract) MigrateKeys(ctx TransactionContextInterface, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, fmt.Errorf("limit should be positive")
+	}
+
+	src, err := openLegacyList(ctx.GetStub())
 	if err != nil {
-		return false, fmt.Errorf("marshal element %q error: %w", a, err)
+		return 0, err
 	}
 
-	secondBlob, err := json.Marshal(&second.Object)
+	unlinked, err := unlinkedKeys(ctx.GetStub(), src.meta.Head, limit)
 	if err != nil {
-		return false, fmt.Errorf("marshal element %q error: %w", b, err)
+		return 0, err
 	}
 
-	fmt.Println(second.Key, string(firstBlob))
+	if len(unlinked) == 0 && src.meta.Length == 0 {
+		return 0, nil
+	}
 
-	if err = ctx.GetStub().PutState(second.Key, firstBlob); err != nil {
-		return false, fmt.Errorf("key %q put context error: %w", second.Key, err)
+	dst, err := openQueue(ctx, DefaultQueue)
+	if errors.Is(err, ErrQueueNotFound) {
+		dst, err = createList(ctx.GetStub(), DefaultQueue)
 	}
 
-	// is that ROLLBACK previous operation
-	if err = ctx.GetStub().PutState(first.Key, secondBlob); err != nil {
-		return false, fmt.Errorf("key %q put first context error: %w", first.Key, err)
+	if err != nil {
+		return 0, err
 	}
 
-	return true, nil
+	if err = dst.allow(accessPush); err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for ; n < limit && n < len(unlinked); n++ {
+		key := unlinked[n]
+
+		v, err := ctx.GetStub().GetState(key)
+		if err != nil {
+			return 0, fmt.Errorf("read key %q error: %w", key, err)
+		}
+
+		e := &entry{}
+		if err = json.Unmarshal(v, e); err != nil {
+			return 0, fmt.Errorf("unmarshal key %q error: %w", key, err)
+		}
+
+		if err = ctx.GetStub().DelState(key); err != nil {
+			return 0, fmt.Errorf("delete key %q error: %w", key, err)
+		}
+
+		if err = migrateKey(ctx, dst, key, e); err != nil {
+			return 0, err
+		}
+	}
+
+	if n < limit && src.meta.Length > 0 {
+		for ; n < limit && src.meta.Head != ""; n++ {
+			key := src.meta.Head
+
+			e, err := src.remove(key)
+			if err != nil {
+				return 0, fmt.Errorf("migrate key %q error: %w", key, err)
+			}
+
+			if err = migrateKey(ctx, dst, key, e); err != nil {
+				return 0, err
+			}
+		}
+
+		if err = src.save(); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = dst.save(); err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// migrateKey append element of flat queue to the tail of dst. Version 2 key is kept when it's still in order
+func migrateKey(ctx TransactionContextInterface, dst *list, key string, e *entry) error {
+	to := key
+	if !strings.HasPrefix(key, KeyV2Prefix) || key <= dst.meta.Tail {
+		t, err := ParseKey(key)
+		if err != nil {
+			return err
+		}
+
+		if to, err = newKey(ctx, dst.meta.Tail, t); err != nil {
+			return err
+		}
+	}
+
+	if err := dst.pushBack(to, e); err != nil {
+		return fmt.Errorf("migrate key %q error: %w", key, err)
+	}
+
+	return nil
 }
@@ -3,23 +3,30 @@
 package leveldb
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 const (
-	Q2011 = "1305619733-758090000"
-	Q2020 = "1589702933-757936000"
+	Q2011 = "v2-001305619733-758090000-00000000-0000"
+	Q2020 = "v2-001589702933-757936000-00000000-0000"
 )
 
+// queue used by TestContract
+const contractQueue = "contract"
+
 func (s *Suite) TestContract() {
 	s.Run("init", func() {
-		res, err := s.contract.InitLedger(s.ctx)
+		res, err := s.contract.InitLedger(s.ctx, contractQueue)
 		s.NoError(err)
 		s.NotEmpty(res)
 	})
 
 	s.Run("Get", func() {
-		res, err := s.contract.Get(s.ctx, Q2020)
+		res, err := s.contract.Get(s.ctx, contractQueue, Q2020)
 		s.NoError(err)
 		s.NotNil(res)
 		s.Equal(res.Key, Q2020)
@@ -28,7 +35,7 @@ func (s *Suite) TestContract() {
 	})
 
 	s.Run("Update", func() {
-		res, err := s.contract.Update(s.ctx, Q2020, `{"country":"NZ"}`)
+		res, err := s.contract.Update(s.ctx, contractQueue, Q2020, `{"country":"NZ"}`)
 		s.NoError(err)
 		s.NotNil(res)
 		s.Equal(res.Key, Q2020)
@@ -37,7 +44,7 @@ func (s *Suite) TestContract() {
 	})
 
 	s.Run("GetAll", func() {
-		res, err := s.contract.GetAll(s.ctx)
+		res, err := s.contract.GetAll(s.ctx, contractQueue)
 		s.NoError(err)
 		s.NotEmpty(res)
 
@@ -48,14 +55,14 @@ func (s *Suite) TestContract() {
 			last := res[len(res)-1]
 			s.NotEqual(first.Object.Context, last.Object.Context)
 
-			ok, err := s.contract.Swap(s.ctx, first.Key, last.Key)
+			ok, err := s.contract.Swap(s.ctx, contractQueue, first.Key, last.Key)
 			s.True(ok)
 			s.NoError(err)
 
-			firstNew, err := s.contract.Get(s.ctx, first.Key)
+			firstNew, err := s.contract.Get(s.ctx, contractQueue, first.Key)
 			s.NoError(err)
 
-			lastNew, err := s.contract.Get(s.ctx, last.Key)
+			lastNew, err := s.contract.Get(s.ctx, contractQueue, last.Key)
 			s.NoError(err)
 
 			s.Equal(first.Object.Context, lastNew.Object.Context)
@@ -65,20 +72,20 @@ func (s *Suite) TestContract() {
 
 	s.Run("GetRange", func() {
 		s.Run("all empty", func() {
-			res, err := s.contract.GetRange(s.ctx, "", "")
+			res, err := s.contract.GetRange(s.ctx, contractQueue, "", "")
 			s.NoError(err)
 			s.NotEmpty(res)
 
 		})
 
 		s.Run("first 0", func() {
-			res, err := s.contract.GetRange(s.ctx, "0", "")
+			res, err := s.contract.GetRange(s.ctx, contractQueue, "0", "")
 			s.NoError(err)
 			s.NotEmpty(res)
 		})
 
 		s.Run("second 2020", func() {
-			res, err := s.contract.GetRange(s.ctx, "0", Q2020)
+			res, err := s.contract.GetRange(s.ctx, contractQueue, "0", Q2020)
 			s.NoError(err)
 			s.NotEmpty(res)
 			fmt.Println(res)
@@ -94,7 +101,7 @@ func (s *Suite) TestContract() {
 		s.Run("selector", func() {
 			// from 2016-05-17T11:08:53.758082+03:00 to 2015-05-17T11:08:53.758084+03:00
 			// where last is exclude, so we should get only 1 result
-			res, err := s.contract.Query(s.ctx, "from=1463472533-758082000&to=1431850133-758084000")
+			res, err := s.contract.Query(s.ctx, contractQueue, "from=v2-001463472533-758082000-00000000-0000&to=v2-001431850133-758084000-00000000-0000")
 			s.NoError(err)
 			s.Len(res, 1)
 
@@ -103,7 +110,7 @@ func (s *Suite) TestContract() {
 
 		s.Run("filter", func() {
 			// fixtures have one field with num equal 10_000_000
-			res, err := s.contract.Query(s.ctx, "filter=num=10000000")
+			res, err := s.contract.Query(s.ctx, contractQueue, "filter=num=10000000")
 			s.NoError(err)
 			s.Len(res, 1)
 
@@ -111,7 +118,7 @@ func (s *Suite) TestContract() {
 		})
 
 		s.Run("sort desc", func() {
-			res, err := s.contract.Query(s.ctx, "sort=-country")
+			res, err := s.contract.Query(s.ctx, contractQueue, "sort=-country")
 			s.NoError(err)
 			s.NotEmpty(res)
 
@@ -120,7 +127,7 @@ func (s *Suite) TestContract() {
 
 
 		s.Run("PushBack", func() {
-			res, err := s.contract.PushBack(s.ctx, `{"country":"PL"}`)
+			res, err := s.contract.PushBack(s.ctx, contractQueue, `{"country":"PL"}`)
 			s.NoError(err)
 			s.NotEmpty(res.Key)
 
@@ -128,19 +135,19 @@ func (s *Suite) TestContract() {
 
 			// and now it's last element
 			s.Run("Back", func() {
-				b, err := s.contract.Back(s.ctx)
+				b, err := s.contract.Back(s.ctx, contractQueue)
 				s.NoError(err)
 				s.Equal(res.Object.Context, b.Object.Context)
 			})
 
 			s.Run("Pop", func() {
-				old, err := s.contract.Pop(s.ctx)
+				old, err := s.contract.Pop(s.ctx, contractQueue)
 				s.NoError(err)
 				s.Equal(res.Object.Context, old.Object.Context)
 
 				// after pop last element is different
 				s.Run("Back", func() {
-					b, err := s.contract.Back(s.ctx)
+					b, err := s.contract.Back(s.ctx, contractQueue)
 					s.NoError(err)
 					s.NotEqual(res.Object.Context, b.Object.Context)
 				})
@@ -148,19 +155,314 @@ func (s *Suite) TestContract() {
 		})
 
 		s.Run("Front", func() {
-			res, err := s.contract.Front(s.ctx)
+			res, err := s.contract.Front(s.ctx, contractQueue)
 			s.NoError(err)
 			s.NotEmpty(res.Key)
 
 			fmt.Println(res)
+
+			s.Run("PopFront", func() {
+				old, err := s.contract.PopFront(s.ctx, contractQueue)
+				s.NoError(err)
+				s.Equal(res, old)
+
+				// after pop first element is different
+				f, err := s.contract.Front(s.ctx, contractQueue)
+				s.NoError(err)
+				s.NotEqual(res.Key, f.Key)
+
+				_, err = s.contract.Get(s.ctx, contractQueue, res.Key)
+				s.Error(err)
+			})
 		})
 
 		s.Run("Delete", func() {
-			err := s.contract.Delete(s.ctx, Q2020)
+			err := s.contract.Delete(s.ctx, contractQueue, Q2020)
 			s.NoError(err)
 
-			_, err = s.contract.Get(s.ctx, Q2020)
+			_, err = s.contract.Get(s.ctx, contractQueue, Q2020)
 			s.Error(err)
 		})
 	})
 }
+
+func (s *Suite) TestEmptyQueue() {
+	const queue = "empty"
+
+	_, err := s.contract.CreateQueue(s.ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.PushBack(s.ctx, queue, `{}`)
+	s.NoError(err)
+
+	// drain everything
+	for {
+		_, err := s.contract.PopFront(s.ctx, queue)
+		if err != nil {
+			s.True(errors.Is(err, ErrEmptyQueue))
+			break
+		}
+	}
+
+	_, err = s.contract.Front(s.ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	_, err = s.contract.Back(s.ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	_, err = s.contract.Pop(s.ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	_, err = s.contract.PopFront(s.ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+}
+
+func (s *Suite) TestMigrateKeys() {
+	base := mustParse("2019-05-17T11:08:53+03:00")
+
+	// elements written by the first version: flat keys without links and metadata.
+	// String order of keys differs from time order: 1558080533-10 < 1558080533-100 < 1558080533-11 < 1558080533-9
+	var legacy []time.Time
+	for _, ns := range []int{100, 11, 10, 9} {
+		t := base.Add(time.Duration(ns))
+
+		item := NewSimpleQueue(t)
+		item.Context["ns"] = ns
+
+		blob, err := json.Marshal(&item)
+		s.NoError(err)
+		s.NoError(s.stub.PutState(TimedKey(t), blob))
+	}
+
+	for _, ns := range []int{9, 10, 11, 100} {
+		legacy = append(legacy, base.Add(time.Duration(ns)))
+	}
+
+	// queue written by previous version: flat keys linked in push order
+	l, err := openLegacyList(s.stub)
+	s.NoError(err)
+	s.Zero(l.meta.Length)
+
+	for _, ns := range []int{9, 10} {
+		t := base.Add(time.Millisecond + time.Duration(ns))
+		legacy = append(legacy, t)
+
+		item := NewSimpleQueue(t)
+		item.Context["ns"] = ns
+
+		s.NoError(l.pushBack(TimedKey(t), &entry{SimpleQueue: item}))
+	}
+
+	// version 2 key is kept as is
+	v2 := TimedKeyV2(base.Add(time.Second), fixtureSuffix)
+	s.NoError(l.pushBack(v2, &entry{SimpleQueue: NewSimpleQueue(base.Add(time.Second))}))
+	legacy = append(legacy, base.Add(time.Second))
+
+	s.NoError(l.save())
+
+	// only elements of the same second are sorted, the rest is not read
+	keys, err := unlinkedKeys(s.stub, l.meta.Head, 2)
+	s.NoError(err)
+	s.Equal([]string{TimedKey(legacy[0]), TimedKey(legacy[1])}, keys)
+
+	// not migrated elements are reported instead of missing or empty queue
+	_, err = s.contract.Front(s.ctx, DefaultQueue)
+	s.True(errors.Is(err, ErrQueueNotFound))
+	s.Contains(err.Error(), "MigrateKeys")
+
+	_, err = s.contract.CreateQueue(s.ctx, DefaultQueue)
+	s.NoError(err)
+
+	_, err = s.contract.PopFront(s.ctx, DefaultQueue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+	s.Contains(err.Error(), "MigrateKeys")
+
+	total := 0
+	for {
+		n, err := s.contract.MigrateKeys(s.ctx, 3)
+		s.NoError(err)
+		s.LessOrEqual(n, 3)
+
+		if n == 0 {
+			break
+		}
+
+		total += n
+	}
+
+	s.Equal(len(legacy), total)
+
+	l, err = openLegacyList(s.stub)
+	s.NoError(err)
+	s.Zero(l.meta.Length)
+
+	keys, err = unlinkedKeys(s.stub, "", DefaultMaxBatch)
+	s.NoError(err)
+	s.Empty(keys)
+
+	// fresh element pushed after upgrade
+	pushed, err := s.contract.PushBack(s.ctx, DefaultQueue, `{"ns":1000}`)
+	s.NoError(err)
+
+	res, err := s.contract.GetAll(s.ctx, DefaultQueue)
+	s.NoError(err)
+	s.Len(res, len(legacy)+1)
+
+	// range order matches push order now
+	for i, t := range legacy {
+		s.True(strings.HasPrefix(res[i].Key, KeyV2Prefix))
+
+		kt, err := ParseKey(res[i].Key)
+		s.NoError(err)
+		s.True(t.Equal(kt))
+		s.True(t.Equal(res[i].Object.Time))
+	}
+
+	s.Equal(v2, res[len(legacy)-1].Key)
+	s.Equal(pushed.Key, res[len(res)-1].Key)
+
+	front, err := s.contract.Front(s.ctx, DefaultQueue)
+	s.NoError(err)
+	s.Equal(res[0], *front)
+
+	back, err := s.contract.Back(s.ctx, DefaultQueue)
+	s.NoError(err)
+	s.Equal(res[len(res)-1], *back)
+
+	_, err = s.contract.MigrateKeys(s.ctx, 0)
+	s.Error(err)
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, DefaultQueue))
+}
+
+func (s *Suite) TestQueues() {
+	for _, name := range []string{"q1", "q2"} {
+		meta, err := s.contract.CreateQueue(s.ctx, name)
+		s.NoError(err)
+		s.Equal(QueueMeta{Name: name}, *meta)
+	}
+
+	_, err := s.contract.CreateQueue(s.ctx, "q1")
+	s.Error(err)
+
+	_, err = s.contract.CreateQueue(s.ctx, "")
+	s.Error(err)
+
+	_, err = s.contract.PushBack(s.ctx, "absent", `{}`)
+	s.True(errors.Is(err, ErrQueueNotFound))
+
+	// same keys in different queues don't interfere
+	a, err := s.contract.PushBack(s.ctx, "q1", `{"q":1}`)
+	s.NoError(err)
+
+	b, err := s.contract.PushBack(s.ctx, "q2", `{"q":2}`)
+	s.NoError(err)
+
+	_, err = s.contract.Get(s.ctx, "q2", a.Key)
+	s.Error(err)
+
+	res, err := s.contract.GetAll(s.ctx, "q1")
+	s.NoError(err)
+	s.Equal([]Query{*a}, res)
+
+	res, err = s.contract.GetAll(s.ctx, "q2")
+	s.NoError(err)
+	s.Equal([]Query{*b}, res)
+
+	queues, err := s.contract.ListQueues(s.ctx)
+	s.NoError(err)
+	s.Contains(queues, QueueMeta{Name: "q1", Head: a.Key, Tail: a.Key, Length: 1, Bytes: s.storedBytes("q1")})
+	s.Contains(queues, QueueMeta{Name: "q2", Head: b.Key, Tail: b.Key, Length: 1, Bytes: s.storedBytes("q2")})
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, "q1"))
+	s.True(errors.Is(s.contract.DeleteQueue(s.ctx, "q1"), ErrQueueNotFound))
+
+	_, err = s.contract.Get(s.ctx, "q2", b.Key)
+	s.NoError(err)
+
+	queues, err = s.contract.ListQueues(s.ctx)
+	s.NoError(err)
+	s.NotContains(queues, QueueMeta{Name: "q1", Head: a.Key, Tail: a.Key, Length: 1, Bytes: s.storedBytes("q1")})
+	s.Contains(queues, QueueMeta{Name: "q2", Head: b.Key, Tail: b.Key, Length: 1, Bytes: s.storedBytes("q2")})
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, "q2"))
+}
+
+// storedBytes sum size of queue elements in the ledger
+func (s *Suite) storedBytes(queue string) int {
+	itr, err := s.stub.GetStateByPartialCompositeKey(itemObjectType, []string{queue})
+	s.Require().NoError(err)
+
+	defer itr.Close()
+
+	n := 0
+	for itr.HasNext() {
+		i, err := itr.Next()
+		s.Require().NoError(err)
+
+		n += len(i.Value)
+	}
+
+	return n
+}
+
+func (s *Suite) TestStats() {
+	const queue = "stats"
+
+	_, err := s.contract.Stats(s.ctx, queue)
+	s.True(errors.Is(err, ErrQueueNotFound))
+
+	_, err = s.contract.CreateQueue(s.ctx, queue)
+	s.NoError(err)
+
+	stats, err := s.contract.Stats(s.ctx, queue)
+	s.NoError(err)
+	s.Equal(QueueStats{Name: queue}, *stats)
+
+	first, err := s.contract.PushBack(s.ctx, queue, `{"n":1}`)
+	s.NoError(err)
+
+	last, err := s.contract.PushBack(s.ctx, queue, `{"n":2}`)
+	s.NoError(err)
+
+	_, err = s.contract.PushBack(s.ctx, queue, `{"n":3}`)
+	s.NoError(err)
+
+	_, err = s.contract.Pop(s.ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.Update(s.ctx, queue, last.Key, `{"payload":"much longer than before"}`)
+	s.NoError(err)
+
+	stats, err = s.contract.Stats(s.ctx, queue)
+	s.NoError(err)
+	s.Equal(2, stats.Length)
+	s.Equal(s.storedBytes(queue), stats.Bytes)
+	s.Equal(first.Key, stats.OldestKey)
+	s.True(first.Object.Time.Equal(stats.OldestCreatedAt))
+	s.Equal(last.Key, stats.NewestKey)
+	s.True(last.Object.Time.Equal(stats.NewestCreatedAt))
+
+	_, err = s.contract.Swap(s.ctx, queue, first.Key, last.Key)
+	s.NoError(err)
+
+	s.NoError(s.contract.Delete(s.ctx, queue, first.Key))
+
+	stats, err = s.contract.Stats(s.ctx, queue)
+	s.NoError(err)
+	s.Equal(1, stats.Length)
+	s.Equal(s.storedBytes(queue), stats.Bytes)
+	s.Equal(last.Key, stats.OldestKey)
+	s.Equal(last.Key, stats.NewestKey)
+
+	_, err = s.contract.PopFront(s.ctx, queue)
+	s.NoError(err)
+
+	stats, err = s.contract.Stats(s.ctx, queue)
+	s.NoError(err)
+	s.Equal(QueueStats{Name: queue}, *stats)
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+}
@@ -0,0 +1,148 @@
+package leveldb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DeadLetter describe why element was moved into dead-letter queue
+type DeadLetter struct {
+	// Queue and Key of element before it was moved
+	Queue string `json:"queue"`
+	Key   string `json:"key"`
+
+	Reason string    `json:"reason"`
+	At     time.Time `json:"at"`
+}
+
+// deadLetters lazily open dead-letter queue of source queue, it's created on first use
+type deadLetters struct {
+	ctx TransactionContextInterface
+	src *list
+	dst *list
+}
+
+// move remove element from source queue and push it into dead-letter queue
+func (d *deadLetters) move(key, reason string) error {
+	if d.dst == nil {
+		name := d.src.meta.deadLetterQueue()
+
+		l, err := openQueue(d.ctx, name)
+		if errors.Is(err, ErrQueueNotFound) {
+			l, err = createList(d.ctx.GetStub(), name)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err = l.allow(accessPush); err != nil {
+			return err
+		}
+
+		d.dst = l
+	}
+
+	now, err := d.ctx.Now()
+	if err != nil {
+		return err
+	}
+
+	e, err := d.src.remove(key)
+	if err != nil {
+		return err
+	}
+
+	item := e.SimpleQueue
+	item.Lease = nil
+	item.DeadLetter = &DeadLetter{Queue: d.src.meta.Name, Key: key, Reason: reason, At: now}
+
+	_, err = push(d.ctx, d.dst, item)
+
+	return err
+}
+
+// save write metadata of dead-letter queue if it was used
+func (d *deadLetters) save() error {
+	if d.dst == nil {
+		return nil
+	}
+
+	return d.dst.save()
+}
+
+// RedriveDeadLetters push back up to limit elements from dead-letter queue of provided queue into it.
+// Elements get fresh keys and creation time like from PushBack, delivery count is reset
+func (s *SimpleQueueContract) RedriveDeadLetters(ctx TransactionContextInterface, queue string, limit int) (res []Query, err error) {
+	if limit <= 0 {
+		return nil, fmt.Errorf("limit should be positive")
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPush); err != nil {
+		return nil, err
+	}
+
+	dlq, err := openQueue(ctx, l.meta.deadLetterQueue())
+	if errors.Is(err, ErrQueueNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err = dlq.allow(accessPop); err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	for key := dlq.meta.Head; key != "" && len(res) < limit; {
+		e, err := dlq.mustGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		next := e.Next
+
+		// dead-letter queue can be shared by several queues
+		if e.DeadLetter == nil || e.DeadLetter.Queue != queue {
+			key = next
+			continue
+		}
+
+		if _, err = dlq.remove(key); err != nil {
+			return nil, err
+		}
+
+		item := NewSimpleQueue(now)
+		item.Context = e.Context
+
+		q, err := push(ctx, l, item)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, *q)
+		key = next
+	}
+
+	if err = dlq.save(); err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
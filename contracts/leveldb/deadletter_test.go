@@ -0,0 +1,92 @@
+// +build unit
+
+package leveldb
+
+import (
+	"time"
+)
+
+func (s *Suite) TestDeadLetter() {
+	const queue = "poison"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"max_receives":-1}`)
+	s.Error(err)
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"dead_letter_queue":"poison"}`)
+	s.Error(err)
+
+	meta, err := s.contract.ConfigureQueue(ctx, queue, `{"max_receives":2}`)
+	s.NoError(err)
+	s.Equal(QueueConfig{MaxReceives: 2}, meta.Config)
+
+	bad, err := s.contract.PushBack(ctx, queue, `{"n":1}`)
+	s.NoError(err)
+
+	good, err := s.contract.PushBack(ctx, queue, `{"n":2}`)
+	s.NoError(err)
+
+	// nothing to redrive yet
+	res, err := s.contract.RedriveDeadLetters(ctx, queue, 10)
+	s.NoError(err)
+	s.Empty(res)
+
+	for i := 1; i <= 2; i++ {
+		res, err := s.contract.Receive(ctx, queue, 1, "1m")
+		s.NoError(err)
+		s.Len(res, 1)
+		s.Equal(bad.Key, res[0].Key)
+		s.Equal(i, res[0].Object.Receives)
+
+		s.NoError(s.contract.Nack(ctx, queue, res[0].Key, res[0].Object.Lease.ID))
+	}
+
+	// third delivery exceeds limit: element moved aside and next one delivered
+	res, err = s.contract.Receive(ctx, queue, 1, "1m")
+	s.NoError(err)
+	s.Len(res, 1)
+	s.Equal(good.Key, res[0].Key)
+
+	_, err = s.contract.Get(ctx, queue, bad.Key)
+	s.Error(err)
+
+	dead, err := s.contract.GetAll(ctx, queue+DeadLetterSuffix)
+	s.NoError(err)
+	s.Len(dead, 1)
+	s.Equal(bad.Object.Context, dead[0].Object.Context)
+	s.Equal(2, dead[0].Object.Receives)
+	s.Nil(dead[0].Object.Lease)
+	s.Equal(&DeadLetter{Queue: queue, Key: bad.Key, Reason: "max receives 2 exceeded", At: now}, dead[0].Object.DeadLetter)
+
+	s.Run("redrive", func() {
+		later := s.clientCtx("alice", now.Add(time.Hour))
+
+		_, err := s.contract.RedriveDeadLetters(later, queue, 0)
+		s.Error(err)
+
+		res, err := s.contract.RedriveDeadLetters(later, queue, 10)
+		s.NoError(err)
+		s.Len(res, 1)
+		s.NotEqual(bad.Key, res[0].Key)
+		s.Equal(bad.Object.Context, res[0].Object.Context)
+		s.True(now.Add(time.Hour).Equal(res[0].Object.Time))
+
+		back, err := s.contract.Back(later, queue)
+		s.NoError(err)
+		s.Equal(res[0], *back)
+		s.Zero(back.Object.Receives)
+		s.Nil(back.Object.DeadLetter)
+
+		dead, err := s.contract.GetAll(later, queue+DeadLetterSuffix)
+		s.NoError(err)
+		s.Empty(dead)
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+	s.NoError(s.contract.DeleteQueue(ctx, queue+DeadLetterSuffix))
+}
@@ -0,0 +1,127 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultDedupWindow how long deduplication ID is remembered when queue doesn't configure it
+const DefaultDedupWindow = 5 * time.Minute
+
+// dedupRecord result of first PushBackIdempotent call with deduplication ID
+type dedupRecord struct {
+	Query Query     `json:"query"`
+	Until time.Time `json:"until"`
+}
+
+// PushBackIdempotent same as PushBack, but repeated call with the same dedupID inside dedup window of the queue
+// return original Query instead of creating new element. Use it for safe retries of timed out submissions
+func (s *SimpleQueueContract) PushBackIdempotent(ctx TransactionContextInterface, queue, dedupID, js string) (*Query, error) {
+	if dedupID == "" {
+		return nil, fmt.Errorf("deduplication ID should not be empty")
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	window := DefaultDedupWindow
+	if l.meta.Config.DedupWindow != "" {
+		if window, err = parseDuration("dedup_window", l.meta.Config.DedupWindow); err != nil {
+			return nil, err
+		}
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(dedupObjectType, []string{queue, dedupID})
+	if err != nil {
+		return nil, fmt.Errorf("create dedup key error: %w", err)
+	}
+
+	v, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("read dedup record error: %w", err)
+	}
+
+	if v != nil {
+		rec := dedupRecord{}
+		if err = json.Unmarshal(v, &rec); err != nil {
+			return nil, fmt.Errorf("unmarshal dedup record error: %w", err)
+		}
+
+		if now.Before(rec.Until) {
+			return &rec.Query, nil
+		}
+	}
+
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := s.pushItem(ctx, queue, item)
+	if err != nil {
+		return nil, err
+	}
+
+	blob, err := json.Marshal(&dedupRecord{Query: *q, Until: now.Add(window)})
+	if err != nil {
+		return nil, fmt.Errorf("marshal dedup record error: %w", err)
+	}
+
+	if err = ctx.GetStub().PutState(key, blob); err != nil {
+		return nil, fmt.Errorf("write dedup record error: %w", err)
+	}
+
+	return q, nil
+}
+
+// purgeDedup delete up to limit deduplication records which window passed
+func purgeDedup(ctx TransactionContextInterface, queue string, now time.Time, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, nil
+	}
+
+	itr, err := ctx.GetStub().GetStateByPartialCompositeKey(dedupObjectType, []string{queue})
+	if err != nil {
+		return 0, fmt.Errorf("can't get range state")
+	}
+
+	var keys []string
+
+	for itr.HasNext() && len(keys) < limit {
+		i, err := itr.Next()
+		if err != nil {
+			_ = itr.Close()
+			return 0, fmt.Errorf("next result error: %w", err)
+		}
+
+		rec := dedupRecord{}
+		if err = json.Unmarshal(i.Value, &rec); err != nil {
+			_ = itr.Close()
+			return 0, fmt.Errorf("unmarshal dedup record error: %w", err)
+		}
+
+		if !now.Before(rec.Until) {
+			keys = append(keys, i.Key)
+		}
+	}
+
+	if err = itr.Close(); err != nil {
+		return 0, fmt.Errorf("close iterator error: %w", err)
+	}
+
+	for _, key := range keys {
+		if err = ctx.GetStub().DelState(key); err != nil {
+			return 0, fmt.Errorf("delete dedup record error: %w", err)
+		}
+	}
+
+	return len(keys), nil
+}
@@ -0,0 +1,76 @@
+// +build unit
+
+package leveldb
+
+import (
+	"time"
+)
+
+func (s *Suite) TestDedup() {
+	const queue = "dedup"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"dedup_window":"never"}`)
+	s.Error(err)
+
+	_, err = s.contract.PushBackIdempotent(ctx, queue, "", `{}`)
+	s.Error(err)
+
+	first, err := s.contract.PushBackIdempotent(ctx, queue, "order-1", `{"n":1}`)
+	s.NoError(err)
+
+	// retry returns original element even with different payload
+	retry, err := s.contract.PushBackIdempotent(s.clientCtx("alice", now.Add(time.Minute)), queue, "order-1", `{"n":2}`)
+	s.NoError(err)
+	s.Equal(first, retry)
+
+	other, err := s.contract.PushBackIdempotent(ctx, queue, "order-2", `{"n":3}`)
+	s.NoError(err)
+	s.NotEqual(first.Key, other.Key)
+
+	stats, err := s.contract.Stats(ctx, queue)
+	s.NoError(err)
+	s.Equal(2, stats.Length)
+
+	s.Run("window passed", func() {
+		later := s.clientCtx("alice", now.Add(DefaultDedupWindow))
+
+		again, err := s.contract.PushBackIdempotent(later, queue, "order-1", `{"n":4}`)
+		s.NoError(err)
+		s.NotEqual(first.Key, again.Key)
+
+		// order-2 record is expired, order-1 record renewed
+		n, err := s.contract.PurgeExpired(later, queue, 10)
+		s.NoError(err)
+		s.Equal(1, n)
+
+		retry, err := s.contract.PushBackIdempotent(later, queue, "order-1", `{}`)
+		s.NoError(err)
+		s.Equal(again, retry)
+	})
+
+	s.Run("configured window", func() {
+		_, err := s.contract.ConfigureQueue(ctx, queue, `{"dedup_window":"1h"}`)
+		s.NoError(err)
+
+		q, err := s.contract.PushBackIdempotent(ctx, queue, "order-3", `{}`)
+		s.NoError(err)
+
+		retry, err := s.contract.PushBackIdempotent(s.clientCtx("alice", now.Add(30*time.Minute)), queue, "order-3", `{}`)
+		s.NoError(err)
+		s.Equal(q, retry)
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+
+	// queue removal drops deduplication index
+	itr, err := s.stub.GetStateByPartialCompositeKey(dedupObjectType, []string{queue})
+	s.NoError(err)
+	s.False(itr.HasNext())
+	s.NoError(itr.Close())
+}
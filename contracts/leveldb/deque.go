@@ -0,0 +1,64 @@
+package leveldb
+
+import (
+	"fmt"
+)
+
+// PushFront create new queue element and put it before the head of queue.
+// Key is generated by FrontKey, so created_at of element stays truthful. Not available in priority mode
+func (s *SimpleQueueContract) PushFront(ctx TransactionContextInterface, queue, js string) (*Query, error) {
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPush); err != nil {
+		return nil, err
+	}
+
+	if l.meta.Config.Mode == QueueModePriority {
+		return nil, fmt.Errorf("queue %q in priority mode: use PushBackPriority", queue)
+	}
+
+	out, err := admit(ctx, l, item, func(e *entry) (string, error) {
+		key, err := newFrontKey(ctx, l.meta.Head, e.Time)
+		if err != nil {
+			return "", err
+		}
+
+		return key, l.insertAfter("", key, e)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// PopBack extract and remove last element of queue. Scheduled, expired and leased elements are skipped
+func (s *SimpleQueueContract) PopBack(ctx TransactionContextInterface, queue string) (*Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return nil, err
+	}
+
+	key, err := s.back(ctx, l)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.pop(l, key)
+}
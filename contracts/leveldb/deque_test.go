@@ -0,0 +1,83 @@
+// +build unit
+
+package leveldb
+
+import (
+	"strings"
+	"time"
+)
+
+func (s *Suite) TestDeque() {
+	const queue = "deque"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	back, err := s.contract.PushBack(ctx, queue, `{"n":"back"}`)
+	s.NoError(err)
+
+	first, err := s.contract.PushFront(ctx, queue, `{"n":"first"}`)
+	s.NoError(err)
+	s.True(strings.HasPrefix(first.Key, FrontKeyPrefix))
+	s.True(now.Equal(first.Object.Time))
+
+	// next one placed before: same time and transaction, but keys differ
+	second, err := s.contract.PushFront(ctx, queue, `{"n":"second"}`)
+	s.NoError(err)
+	s.Less(second.Key, first.Key)
+	s.True(now.Equal(second.Object.Time))
+
+	// new context restarts sequence, key is clamped before the head
+	third, err := s.contract.PushFront(s.clientCtx("alice", now), queue, `{"n":"third"}`)
+	s.NoError(err)
+	s.Less(third.Key, second.Key)
+
+	later, err := s.contract.PushFront(s.clientCtx("alice", now.Add(time.Hour)), queue, `{"n":"later"}`)
+	s.NoError(err)
+	s.Less(later.Key, third.Key)
+	s.True(now.Add(time.Hour).Equal(later.Object.Time))
+
+	t, err := ParseKey(later.Key)
+	s.NoError(err)
+	s.True(now.Add(time.Hour).Equal(t))
+
+	res, err := s.contract.GetAll(ctx, queue)
+	s.NoError(err)
+	s.Equal([]Query{*later, *third, *second, *first, *back}, res)
+
+	f, err := s.contract.PopFront(ctx, queue)
+	s.NoError(err)
+	s.Equal(later.Key, f.Key)
+
+	b, err := s.contract.PopBack(ctx, queue)
+	s.NoError(err)
+	s.Equal(back.Key, b.Key)
+
+	// tail is front key now, back push still goes after it
+	tail, err := s.contract.PushBack(ctx, queue, `{"n":"tail"}`)
+	s.NoError(err)
+
+	b, err = s.contract.Pop(ctx, queue)
+	s.NoError(err)
+	s.Equal(tail.Key, b.Key)
+
+	s.Run("priority", func() {
+		const queue = "deque-priority"
+
+		_, err := s.contract.CreateQueue(ctx, queue)
+		s.NoError(err)
+
+		_, err = s.contract.ConfigureQueue(ctx, queue, `{"mode":"priority"}`)
+		s.NoError(err)
+
+		_, err = s.contract.PushFront(ctx, queue, `{}`)
+		s.Error(err)
+
+		s.NoError(s.contract.DeleteQueue(ctx, queue))
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
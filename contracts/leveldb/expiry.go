@@ -0,0 +1,109 @@
+package leveldb
+
+import (
+	"fmt"
+)
+
+// newExpiry return expiry of element which lives ttl since transaction time
+func newExpiry(ctx TransactionContextInterface, ttl string) (*Expiry, error) {
+	d, err := parseDuration("ttl", ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Expiry{At: now.Add(d)}, nil
+}
+
+// PushBackTTL create new queue element which is removed from all reads after ttl.
+// Overrides default TTL of the queue
+func (s *SimpleQueueContract) PushBackTTL(ctx TransactionContextInterface, queue, js, ttl string) (*Query, error) {
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	if item.Expiry, err = newExpiry(ctx, ttl); err != nil {
+		return nil, err
+	}
+
+	return s.pushItem(ctx, queue, item)
+}
+
+// UpdateTTL same as Update but also restart lifetime of element. Empty ttl removes expiry
+func (s *SimpleQueueContract) UpdateTTL(ctx TransactionContextInterface, queue, key, js, ttl string) (*Query, error) {
+	var expiry *Expiry
+
+	if ttl != "" {
+		var err error
+		if expiry, err = newExpiry(ctx, ttl); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.update(ctx, queue, key, js, func(item *SimpleQueue) {
+		item.Expiry = expiry
+	})
+}
+
+// PurgeExpired delete up to limit expired elements, elements which outlived retention of the queue and
+// deduplication records.
+// Returns amount of deleted records, call it until zero returned
+func (s *SimpleQueueContract) PurgeExpired(ctx TransactionContextInterface, queue string, limit int) (int, error) {
+	if limit <= 0 {
+		return 0, fmt.Errorf("limit should be positive")
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return 0, err
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return 0, err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return 0, err
+	}
+
+	retention, err := l.meta.retention()
+	if err != nil {
+		return 0, err
+	}
+
+	var keys []string
+
+	err = l.scan(func(key string, e *entry) (bool, error) {
+		if e.Expired(now) || retained(retention, e.Time, now) {
+			keys = append(keys, key)
+		}
+
+		return len(keys) < limit, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, key := range keys {
+		if _, err = l.remove(key); err != nil {
+			return 0, err
+		}
+	}
+
+	if err = l.save(); err != nil {
+		return 0, err
+	}
+
+	n, err := purgeDedup(ctx, queue, now, limit-len(keys))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(keys) + n, nil
+}
@@ -0,0 +1,130 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+	"time"
+)
+
+func (s *Suite) TestExpiry() {
+	const queue = "expiry"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"ttl":"-1h"}`)
+	s.Error(err)
+
+	_, err = s.contract.PushBackTTL(ctx, queue, `{}`, "0s")
+	s.Error(err)
+
+	short, err := s.contract.PushBackTTL(ctx, queue, `{"n":1}`, "1m")
+	s.NoError(err)
+	s.True(now.Add(time.Minute).Equal(short.Object.Expiry.At))
+
+	forever, err := s.contract.PushBack(ctx, queue, `{"n":2}`)
+	s.NoError(err)
+	s.Nil(forever.Object.Expiry)
+
+	meta, err := s.contract.ConfigureQueue(ctx, queue, `{"ttl":"1h"}`)
+	s.NoError(err)
+	s.Equal("1h", meta.Config.TTL)
+
+	hour, err := s.contract.PushBack(ctx, queue, `{"n":3}`)
+	s.NoError(err)
+	s.True(now.Add(time.Hour).Equal(hour.Object.Expiry.At))
+
+	// per element TTL wins over queue default
+	long, err := s.contract.PushBackTTL(ctx, queue, `{"n":4}`, "2h")
+	s.NoError(err)
+	s.True(now.Add(2 * time.Hour).Equal(long.Object.Expiry.At))
+
+	s.Run("Update", func() {
+		res, err := s.contract.UpdateTTL(ctx, queue, short.Key, `{"updated":true}`, "")
+		s.NoError(err)
+		s.Nil(res.Object.Expiry)
+		s.Equal(true, res.Object.Context["updated"])
+
+		res, err = s.contract.UpdateTTL(ctx, queue, short.Key, "", "1m")
+		s.NoError(err)
+		s.True(now.Add(time.Minute).Equal(res.Object.Expiry.At))
+
+		_, err = s.contract.UpdateTTL(ctx, queue, short.Key, "", "soon")
+		s.Error(err)
+	})
+
+	s.Run("hidden", func() {
+		later := s.clientCtx("alice", now.Add(time.Hour))
+
+		front, err := s.contract.Front(later, queue)
+		s.NoError(err)
+		s.Equal(forever.Key, front.Key)
+
+		back, err := s.contract.Back(later, queue)
+		s.NoError(err)
+		s.Equal(long.Key, back.Key)
+
+		_, err = s.contract.Get(later, queue, short.Key)
+		s.Error(err)
+
+		q, err := s.contract.Get(ctx, queue, short.Key)
+		s.NoError(err)
+		s.Equal(short.Key, q.Key)
+
+		res, err := s.contract.GetRange(later, queue, "", "")
+		s.NoError(err)
+		s.Len(res, 2)
+
+		res, err = s.contract.Query(later, queue, "")
+		s.NoError(err)
+		s.Len(res, 2)
+
+		leased, err := s.contract.Receive(later, queue, 10, "1m")
+		s.NoError(err)
+		s.Len(leased, 2)
+
+		// only element without expiry left alive
+		end := s.clientCtx("alice", now.Add(2*time.Hour))
+
+		old, err := s.contract.Pop(end, queue)
+		s.NoError(err)
+		s.Equal(forever.Key, old.Key)
+
+		_, err = s.contract.Back(end, queue)
+		s.True(errors.Is(err, ErrEmptyQueue))
+
+		_, err = s.contract.Front(end, queue)
+		s.True(errors.Is(err, ErrEmptyQueue))
+	})
+
+	s.Run("PurgeExpired", func() {
+		later := s.clientCtx("alice", now.Add(time.Hour))
+
+		_, err := s.contract.PurgeExpired(later, queue, 0)
+		s.Error(err)
+
+		n, err := s.contract.PurgeExpired(later, queue, 1)
+		s.NoError(err)
+		s.Equal(1, n)
+
+		n, err = s.contract.PurgeExpired(later, queue, 10)
+		s.NoError(err)
+		s.Equal(1, n)
+
+		n, err = s.contract.PurgeExpired(later, queue, 10)
+		s.NoError(err)
+		s.Zero(n)
+
+		stats, err := s.contract.Stats(later, queue)
+		s.NoError(err)
+		s.Equal(1, stats.Length)
+		s.Equal(long.Key, stats.OldestKey)
+		s.Equal(s.storedBytes(queue), stats.Bytes)
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
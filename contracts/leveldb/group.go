@@ -0,0 +1,355 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ConsumerGroup independent reader of the queue. Cursor is the last committed key, empty cursor means nothing
+// was committed yet and reading starts from the head
+type ConsumerGroup struct {
+	Queue  string `json:"queue"`
+	Name   string `json:"name"`
+	Cursor string `json:"cursor"`
+}
+
+// groupKey return ledger key of consumer group record
+func groupKey(ctx TransactionContextInterface, queue, group string) (string, error) {
+	if group == "" {
+		return "", fmt.Errorf("group name should not be empty")
+	}
+
+	key, err := ctx.GetStub().CreateCompositeKey(groupObjectType, []string{queue, group})
+	if err != nil {
+		return "", fmt.Errorf("create group key error: %w", err)
+	}
+
+	return key, nil
+}
+
+// getGroup read consumer group record or nil if it not exists
+func getGroup(ctx TransactionContextInterface, queue, group string) (*ConsumerGroup, error) {
+	key, err := groupKey(ctx, queue, group)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := ctx.GetStub().GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("read group %q error: %w", group, err)
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	g := &ConsumerGroup{}
+	if err = json.Unmarshal(v, g); err != nil {
+		return nil, fmt.Errorf("unmarshal group %q error: %w", group, err)
+	}
+
+	return g, nil
+}
+
+// mustGetGroup same as getGroup but absent group is an error
+func mustGetGroup(ctx TransactionContextInterface, queue, group string) (*ConsumerGroup, error) {
+	g, err := getGroup(ctx, queue, group)
+	if err != nil {
+		return nil, err
+	}
+
+	if g == nil {
+		return nil, fmt.Errorf("group %q of queue %q not exists", group, queue)
+	}
+
+	return g, nil
+}
+
+// putGroup write consumer group record
+func putGroup(ctx TransactionContextInterface, g *ConsumerGroup) error {
+	key, err := groupKey(ctx, g.Queue, g.Name)
+	if err != nil {
+		return err
+	}
+
+	blob, err := json.Marshal(g)
+	if err != nil {
+		return fmt.Errorf("marshal group %q error: %w", g.Name, err)
+	}
+
+	if err = ctx.GetStub().PutState(key, blob); err != nil {
+		return fmt.Errorf("write group %q error: %w", g.Name, err)
+	}
+
+	return nil
+}
+
+// retention return how long elements are kept regardless of consumer groups, zero when not configured
+func (m *QueueMeta) retention() (time.Duration, error) {
+	if m.Config.Retention == "" {
+		return 0, nil
+	}
+
+	return parseDuration("retention", m.Config.Retention)
+}
+
+// retained report whether element created at t outlived retention of the queue
+func retained(retention time.Duration, t, now time.Time) bool {
+	return retention > 0 && !now.Before(t.Add(retention))
+}
+
+// trim delete up to limit elements from the head which every consumer group passed or which outlived retention.
+// Fabric doesn't read own writes, so group changed by current transaction is provided explicitly
+func trim(ctx TransactionContextInterface, l *list, changed *ConsumerGroup, limit int) (int, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return 0, err
+	}
+
+	retention, err := l.meta.retention()
+	if err != nil {
+		return 0, err
+	}
+
+	groups, err := listGroups(ctx, l.meta.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	// without groups nobody passed elements
+	passed := ""
+
+	for i, g := range groups {
+		if changed != nil && g.Name == changed.Name {
+			g = *changed
+		}
+
+		if i == 0 || g.Cursor < passed {
+			passed = g.Cursor
+		}
+	}
+
+	n := 0
+	for ; n < limit && l.meta.Head != ""; n++ {
+		key := l.meta.Head
+
+		e, err := l.mustGet(key)
+		if err != nil {
+			return 0, err
+		}
+
+		if key > passed && !retained(retention, e.Time, now) {
+			break
+		}
+
+		if _, err = l.remove(key); err != nil {
+			return 0, err
+		}
+	}
+
+	return n, nil
+}
+
+// CreateGroup register consumer group which starts reading from the head of queue.
+// Once queue has groups, committed elements are deleted only when every group passed them
+func (s *SimpleQueueContract) CreateGroup(ctx TransactionContextInterface, queue, group string) (*ConsumerGroup, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessAdmin); err != nil {
+		return nil, err
+	}
+
+	g, err := getGroup(ctx, queue, group)
+	if err != nil {
+		return nil, err
+	}
+
+	if g != nil {
+		return nil, fmt.Errorf("group %q of queue %q already exists", group, queue)
+	}
+
+	g = &ConsumerGroup{Queue: queue, Name: group}
+	if err = putGroup(ctx, g); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
+
+// DeleteGroup remove consumer group. Elements it didn't pass are deleted by next Commit of other groups
+func (s *SimpleQueueContract) DeleteGroup(ctx TransactionContextInterface, queue, group string) error {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return err
+	}
+
+	if err = l.allow(accessAdmin); err != nil {
+		return err
+	}
+
+	if _, err = mustGetGroup(ctx, queue, group); err != nil {
+		return err
+	}
+
+	key, err := groupKey(ctx, queue, group)
+	if err != nil {
+		return err
+	}
+
+	if err = ctx.GetStub().DelState(key); err != nil {
+		return fmt.Errorf("delete group %q error: %w", group, err)
+	}
+
+	return nil
+}
+
+// ListGroups return consumer groups of queue ordered by name
+func (s *SimpleQueueContract) ListGroups(ctx TransactionContextInterface, queue string) ([]ConsumerGroup, error) {
+	if _, err := openQueue(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	return listGroups(ctx, queue)
+}
+
+// listGroups read all consumer group records of queue
+func listGroups(ctx TransactionContextInterface, queue string) (res []ConsumerGroup, err error) {
+	itr, err := ctx.GetStub().GetStateByPartialCompositeKey(groupObjectType, []string{queue})
+	if err != nil {
+		return nil, fmt.Errorf("can't get range state")
+	}
+
+	defer itr.Close()
+
+	for itr.HasNext() {
+		i, err := itr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("next result error: %w", err)
+		}
+
+		g := ConsumerGroup{}
+		if err = json.Unmarshal(i.Value, &g); err != nil {
+			return nil, fmt.Errorf("unmarshal error: %w", err)
+		}
+
+		res = append(res, g)
+	}
+
+	return res, nil
+}
+
+// ReadNext return up to n elements after the cursor of group without deleting them. Scheduled, expired and leased
+// elements are skipped. Elements placed before the cursor after it was committed (PushFront, priority, moves) are not read
+func (s *SimpleQueueContract) ReadNext(ctx TransactionContextInterface, queue, group string, n int) (res []Query, err error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkBatch(l, n); err != nil {
+		return nil, err
+	}
+
+	g, err := mustGetGroup(ctx, queue, group)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.meta.Head
+
+	if g.Cursor != "" {
+		e, err := l.get(g.Cursor)
+		if err != nil {
+			return nil, err
+		}
+
+		if e != nil {
+			key = e.Next
+		}
+	}
+
+	// committed element was deleted: list order is key order, so skip everything up to the cursor
+	for key != "" && key <= g.Cursor {
+		e, err := l.mustGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		key = e.Next
+	}
+
+	for skipped := 0; key != "" && len(res) < n; {
+		if err = checkWalk(l, skipped); err != nil {
+			if len(res) > 0 {
+				break
+			}
+
+			return nil, err
+		}
+
+		e, err := l.mustGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Visible(now) && !e.Expired(now) {
+			res = append(res, Query{key, e.SimpleQueue})
+		} else {
+			skipped++
+		}
+
+		key = e.Next
+	}
+
+	return res, nil
+}
+
+// Commit advance cursor of group to provided key which should be after the current cursor.
+// Elements passed by every group and elements which outlived retention are deleted from the head,
+// up to max_batch per transaction
+func (s *SimpleQueueContract) Commit(ctx TransactionContextInterface, queue, group, key string) (*ConsumerGroup, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return nil, err
+	}
+
+	g, err := mustGetGroup(ctx, queue, group)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case key <= g.Cursor:
+		return nil, fmt.Errorf("key %q is not after cursor %q of group %q", key, g.Cursor, group)
+	case key > l.meta.Tail:
+		return nil, fmt.Errorf("key %q is after the tail of queue %q", key, queue)
+	}
+
+	g.Cursor = key
+	if err = putGroup(ctx, g); err != nil {
+		return nil, err
+	}
+
+	if _, err = trim(ctx, l, g, l.meta.maxBatch()); err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
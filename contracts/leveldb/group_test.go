@@ -0,0 +1,134 @@
+// +build unit
+
+package leveldb
+
+import (
+	"time"
+)
+
+func (s *Suite) TestConsumerGroups() {
+	const queue = "groups"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	items, err := s.contract.PushBackBatch(ctx, queue, `[{"n":0},{"n":1},{"n":2},{"n":3},{"n":4}]`)
+	s.NoError(err)
+
+	for _, name := range []string{"billing", "audit"} {
+		g, err := s.contract.CreateGroup(ctx, queue, name)
+		s.NoError(err)
+		s.Equal(ConsumerGroup{Queue: queue, Name: name}, *g)
+	}
+
+	_, err = s.contract.CreateGroup(ctx, queue, "audit")
+	s.Error(err)
+
+	groups, err := s.contract.ListGroups(ctx, queue)
+	s.NoError(err)
+	s.Len(groups, 2)
+	s.Equal("audit", groups[0].Name)
+
+	length := func() int {
+		stats, err := s.contract.Stats(ctx, queue)
+		s.NoError(err)
+		s.Equal(s.storedBytes(queue), stats.Bytes)
+
+		return stats.Length
+	}
+
+	// reading doesn't move cursor
+	res, err := s.contract.ReadNext(ctx, queue, "billing", 2)
+	s.NoError(err)
+	s.Equal(items[:2], res)
+
+	res, err = s.contract.ReadNext(ctx, queue, "billing", 2)
+	s.NoError(err)
+	s.Equal(items[:2], res)
+
+	g, err := s.contract.Commit(ctx, queue, "billing", res[1].Key)
+	s.NoError(err)
+	s.Equal(res[1].Key, g.Cursor)
+
+	// audit didn't pass anything
+	s.Equal(5, length())
+
+	res, err = s.contract.ReadNext(ctx, queue, "billing", 10)
+	s.NoError(err)
+	s.Equal(items[2:], res)
+
+	_, err = s.contract.Commit(ctx, queue, "billing", items[0].Key)
+	s.Error(err, "cursor can't go back")
+
+	_, err = s.contract.Commit(ctx, queue, "billing", items[4].Key+"~")
+	s.Error(err, "cursor can't pass the tail")
+
+	_, err = s.contract.Commit(ctx, queue, "audit", items[0].Key)
+	s.NoError(err)
+	s.Equal(4, length())
+
+	// both groups passed first 2 elements
+	_, err = s.contract.Commit(ctx, queue, "audit", items[3].Key)
+	s.NoError(err)
+	s.Equal(3, length())
+
+	// committed element is deleted, reading continues after it
+	_, err = s.contract.Commit(ctx, queue, "billing", items[2].Key)
+	s.NoError(err)
+	s.Equal(2, length())
+
+	res, err = s.contract.ReadNext(ctx, queue, "billing", 10)
+	s.NoError(err)
+	s.Equal(items[3:], res)
+
+	// deleted lagging group doesn't hold elements
+	s.NoError(s.contract.DeleteGroup(ctx, queue, "billing"))
+	s.Error(s.contract.DeleteGroup(ctx, queue, "billing"))
+
+	_, err = s.contract.Commit(ctx, queue, "audit", items[4].Key)
+	s.NoError(err)
+	s.Equal(0, length())
+
+	_, err = s.contract.ReadNext(ctx, queue, "billing", 1)
+	s.Error(err)
+
+	s.Run("retention", func() {
+		_, err := s.contract.ConfigureQueue(ctx, queue, `{"retention":"1h"}`)
+		s.NoError(err)
+
+		old, err := s.contract.PushBack(ctx, queue, `{}`)
+		s.NoError(err)
+
+		fresh, err := s.contract.PushBack(s.clientCtx("alice", now.Add(time.Hour)), queue, `{}`)
+		s.NoError(err)
+
+		later := s.clientCtx("alice", now.Add(90*time.Minute))
+
+		n, err := s.contract.PurgeExpired(later, queue, 10)
+		s.NoError(err)
+		s.Equal(1, n)
+
+		res, err := s.contract.ReadNext(later, queue, "audit", 10)
+		s.NoError(err)
+		s.Equal([]Query{*fresh}, res)
+		s.NotEqual(old.Key, res[0].Key)
+
+		_, err = s.contract.ConfigureQueue(ctx, queue, `{"retention":"forever"}`)
+		s.Error(err)
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+
+	// groups are deleted with the queue
+	_, err = s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	groups, err = s.contract.ListGroups(ctx, queue)
+	s.NoError(err)
+	s.Empty(groups)
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
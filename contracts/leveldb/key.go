@@ -0,0 +1,195 @@
+package leveldb
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// KeyV2Prefix marks keys generated by TimedKeyV2.
+// Every TimedKey starts with digit, so version 2 keys always sorted after them
+const KeyV2Prefix = "v2-"
+
+// FrontKeyPrefix marks keys generated by FrontKey. It's sorted before KeyV2Prefix
+const FrontKeyPrefix = "v2+"
+
+// maximal values of inverted FrontKey parts
+const (
+	maxFrontSeconds = 999999999999
+	maxFrontNanos   = 999999999
+	maxFrontSeq     = 9999
+)
+
+// PriorityKeyPrefix marks keys generated by PriorityKey
+const PriorityKeyPrefix = "p-"
+
+// fixtureSuffix used by InitLedger to have well known keys
+const fixtureSuffix = "00000000-0000"
+
+// TimedKey generate unique queue value.
+// For sake of decreasing collision we use nanosecond postfix
+// But collision possible, for now it's low chance but for handling that we should make a lot more stuff
+//
+// Deprecated: keys are not padded, so lexicographic order differs from time order. Use TimedKeyV2
+func TimedKey(time time.Time) string {
+	return fmt.Sprintf("%d-%d", time.Unix(), time.Nanosecond())
+}
+
+// TimedKeyV2 generate versioned key with fixed width seconds and nanoseconds, so lexicographic order of keys
+// is equal to time order. Suffix resolve collision of elements created at the same nanosecond
+//
+// example: v2-001589702933-757936000-9f86d081-0000
+func TimedKeyV2(t time.Time, suffix string) string {
+	return fmt.Sprintf("%s%012d-%09d-%s", KeyV2Prefix, t.Unix(), t.Nanosecond(), suffix)
+}
+
+// FrontKey generate key of element pushed to the front of queue. Time parts are inverted, so later elements
+// sorted before earlier ones and before any TimedKeyV2 key. Suffix is expected from frontSuffix
+//
+// example: v2+998410297066-242063999-9f86d081-9999
+func FrontKey(t time.Time, suffix string) string {
+	return fmt.Sprintf("%s%012d-%09d-%s", FrontKeyPrefix, maxFrontSeconds-t.Unix(), maxFrontNanos-t.Nanosecond(), suffix)
+}
+
+// frontSuffix same as TxSuffix with inverted sequence number
+func frontSuffix(txID string, seq int) string {
+	return TxSuffix(txID, maxFrontSeq-seq)
+}
+
+// PriorityKey prepend TimedKeyV2 key with inverted priority, so higher priority keys sorted first
+//
+// example: p-9989-v2-001589702933-757936000-9f86d081-0000 for priority 10
+func PriorityKey(priority int, key string) string {
+	return priorityBand(priority) + key
+}
+
+// priorityBand common prefix of keys with provided priority
+func priorityBand(priority int) string {
+	return fmt.Sprintf("%s%04d-", PriorityKeyPrefix, MaxPriority-priority)
+}
+
+// TxSuffix derive key suffix from transaction ID and sequence number of key inside transaction
+func TxSuffix(txID string, seq int) string {
+	sum := sha256.Sum256([]byte(txID))
+
+	return fmt.Sprintf("%x-%04d", sum[:4], seq)
+}
+
+// ParseKey extract time from TimedKey, TimedKeyV2, FrontKey or PriorityKey key
+func ParseKey(key string) (time.Time, error) {
+	var parts []string
+
+	if strings.HasPrefix(key, FrontKeyPrefix) {
+		t, err := ParseKey(KeyV2Prefix + strings.TrimPrefix(key, FrontKeyPrefix))
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		return time.Unix(maxFrontSeconds-t.Unix(), int64(maxFrontNanos-t.Nanosecond())).UTC(), nil
+	}
+
+	if strings.HasPrefix(key, PriorityKeyPrefix) {
+		band := len(PriorityKeyPrefix) + 5
+		if len(key) <= band || key[band-1] != '-' {
+			return time.Time{}, fmt.Errorf("key %q has wrong priority format", key)
+		}
+
+		return ParseKey(key[band:])
+	}
+
+	if strings.HasPrefix(key, KeyV2Prefix) {
+		parts = strings.SplitN(strings.TrimPrefix(key, KeyV2Prefix), "-", 3)
+		if len(parts) != 3 {
+			return time.Time{}, fmt.Errorf("key %q has wrong v2 format", key)
+		}
+	} else {
+		parts = strings.Split(key, "-")
+		if len(parts) != 2 {
+			return time.Time{}, fmt.Errorf("key %q has wrong format", key)
+		}
+	}
+
+	sec, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("key %q seconds parse error: %w", key, err)
+	}
+
+	nsec, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("key %q nanoseconds parse error: %w", key, err)
+	}
+
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+// newKey generate key for element created at t which should be placed after provided key.
+// Transaction timestamp is set by client, so it can be behind the key of queue tail.
+// In such case key time is shifted right after the tail, created_at of element stay untouched
+func newKey(ctx TransactionContextInterface, after string, t time.Time) (string, error) {
+	suffix := TxSuffix(ctx.GetStub().GetTxID(), ctx.Sequence())
+
+	key := TimedKeyV2(t, suffix)
+	if key > after {
+		return key, nil
+	}
+
+	last, err := ParseKey(after)
+	if err != nil {
+		return "", err
+	}
+
+	return TimedKeyV2(last.Add(time.Nanosecond), suffix), nil
+}
+
+// newFrontKey generate key for element created at t which should be placed before provided key.
+// Same as newKey clamps the key to be less than key of the head
+func newFrontKey(ctx TransactionContextInterface, before string, t time.Time) (string, error) {
+	suffix := frontSuffix(ctx.GetStub().GetTxID(), ctx.Sequence())
+
+	key := FrontKey(t, suffix)
+	if before == "" || key < before {
+		return key, nil
+	}
+
+	first, err := ParseKey(before)
+	if err != nil {
+		return "", err
+	}
+
+	return FrontKey(first.Add(time.Nanosecond), suffix), nil
+}
+
+// bounds of characters appended by midKey. Queue keys consist of printable ASCII characters
+const (
+	minMidChar = '!'
+	maxMidChar = '~'
+)
+
+// midKey generate key which is sorted strictly between prev and next. Used when element is moved between
+// neighbours: prev is extended with suffix, so ParseKey still return time of prev and created_at of moved
+// element stays untouched. Generated keys never end with minMidChar, so there is always room for another key
+func midKey(prev, next string) string {
+	if !strings.HasPrefix(next, prev) {
+		return prev + string(maxMidChar)
+	}
+
+	return prev + midSuffix(next[len(prev):])
+}
+
+// midSuffix return non empty suffix which is less than rest
+func midSuffix(rest string) string {
+	c := int(rest[0])
+
+	switch {
+	case c > maxMidChar:
+		return string(maxMidChar)
+	case c > minMidChar+1:
+		return string(rune((minMidChar + c) / 2))
+	case c == minMidChar+1:
+		return string(minMidChar) + string(maxMidChar)
+	default:
+		return string(minMidChar) + midSuffix(rest[1:])
+	}
+}
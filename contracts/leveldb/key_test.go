@@ -0,0 +1,212 @@
+// +build unit
+
+package leveldb
+
+import (
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestTimedKeyV2(t *testing.T) {
+	base := mustParse("2020-05-17T11:08:53+03:00")
+
+	times := []time.Time{
+		base.Add(10 * time.Nanosecond),
+		base.Add(9 * time.Nanosecond),
+		base.Add(time.Second),
+		base,
+	}
+
+	keys := make([]string, len(times))
+	for i := range times {
+		keys[i] = TimedKeyV2(times[i], TxSuffix("tx", i))
+	}
+
+	sort.Strings(keys)
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	for i := range keys {
+		got, err := ParseKey(keys[i])
+		if err != nil {
+			t.Fatalf("ParseKey() error = %v", err)
+		}
+
+		if !got.Equal(times[i]) {
+			t.Errorf("key %q order mismatch: got %v want %v", keys[i], got, times[i])
+		}
+	}
+
+	if TimedKeyV2(base, TxSuffix("tx", 0)) == TimedKeyV2(base, TxSuffix("tx", 1)) {
+		t.Errorf("keys of the same time and transaction collide")
+	}
+
+	if TimedKeyV2(base, TxSuffix("tx1", 0)) == TimedKeyV2(base, TxSuffix("tx2", 0)) {
+		t.Errorf("keys of the same time and different transactions collide")
+	}
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		want    time.Time
+		wantErr bool
+	}{
+		{
+			name: "v1",
+			key:  "1589702933-757936000",
+			want: mustParse("2020-05-17T11:08:53.757936+03:00"),
+		},
+		{
+			name: "v1 not padded",
+			key:  "1589702933-9",
+			want: mustParse("2020-05-17T11:08:53.000000009+03:00"),
+		},
+		{
+			name: "v2",
+			key:  "v2-001589702933-757936000-00000000-0000",
+			want: mustParse("2020-05-17T11:08:53.757936+03:00"),
+		},
+		{
+			name:    "v2 without suffix",
+			key:     "v2-001589702933-757936000",
+			wantErr: true,
+		},
+		{
+			name: "priority",
+			key:  "p-9989-v2-001589702933-757936000-00000000-0000",
+			want: mustParse("2020-05-17T11:08:53.757936+03:00"),
+		},
+		{
+			name: "front",
+			key:  "v2+998410297066-242063999-00000000-9999",
+			want: mustParse("2020-05-17T11:08:53.757936+03:00"),
+		},
+		{
+			name:    "front bad number",
+			key:     "v2+99841029706X-242063999-00000000-9999",
+			wantErr: true,
+		},
+		{
+			name:    "priority without band",
+			key:     "p-v2-001589702933-757936000-00000000-0000",
+			wantErr: true,
+		},
+		{
+			name:    "bad format",
+			key:     "country",
+			wantErr: true,
+		},
+		{
+			name:    "bad number",
+			key:     "1589702933-XXX",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseKey() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseKey() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriorityKey(t *testing.T) {
+	old := TimedKeyV2(mustParse("2019-05-17T11:08:53+03:00"), fixtureSuffix)
+	fresh := TimedKeyV2(mustParse("2020-05-17T11:08:53+03:00"), fixtureSuffix)
+
+	// higher priority first, then older
+	want := []string{
+		PriorityKey(MaxPriority, fresh),
+		PriorityKey(10, old),
+		PriorityKey(10, fresh),
+		PriorityKey(9, old),
+		PriorityKey(0, old),
+	}
+
+	keys := append([]string(nil), want...)
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	sort.Strings(keys)
+
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("position %d: got %q want %q", i, keys[i], want[i])
+		}
+	}
+}
+
+func TestFrontKey(t *testing.T) {
+	base := mustParse("2020-05-17T11:08:53+03:00")
+
+	back := TimedKeyV2(base.Add(-time.Hour), TxSuffix("tx", 0))
+
+	// pushed later means placed before
+	want := []string{
+		FrontKey(base.Add(time.Second), frontSuffix("tx", 1)),
+		FrontKey(base.Add(time.Second), frontSuffix("tx", 0)),
+		FrontKey(base.Add(time.Nanosecond), frontSuffix("tx", 0)),
+		FrontKey(base, frontSuffix("tx", 0)),
+		back,
+	}
+
+	keys := append([]string(nil), want...)
+	sort.Sort(sort.Reverse(sort.StringSlice(keys)))
+	sort.Strings(keys)
+
+	for i := range want {
+		if keys[i] != want[i] {
+			t.Errorf("position %d: got %q want %q", i, keys[i], want[i])
+		}
+	}
+
+	got, err := ParseKey(want[0])
+	if err != nil {
+		t.Fatalf("ParseKey() error = %v", err)
+	}
+
+	if !got.Equal(base.Add(time.Second)) {
+		t.Errorf("ParseKey() got = %v, want %v", got, base.Add(time.Second))
+	}
+}
+
+func TestMidKey(t *testing.T) {
+	base := mustParse("2020-05-17T11:08:53+03:00")
+
+	prev := TimedKeyV2(base, TxSuffix("tx", 0))
+	next := TimedKeyV2(base, TxSuffix("tx", 1))
+
+	// squeeze keys towards prev and towards next
+	for _, toPrev := range []bool{true, false} {
+		lo, hi := prev, next
+
+		for i := 0; i < 200; i++ {
+			mid := midKey(lo, hi)
+			if mid <= lo || mid >= hi {
+				t.Fatalf("step %d: %q not between %q and %q", i, mid, lo, hi)
+			}
+
+			if toPrev {
+				hi = mid
+			} else {
+				lo = mid
+			}
+		}
+
+		got, err := ParseKey(lo)
+		if err != nil {
+			t.Fatalf("ParseKey() error = %v", err)
+		}
+
+		if !got.Equal(base) {
+			t.Errorf("ParseKey() got = %v, want %v", got, base)
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package leveldb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidLease returned by Ack and Nack when caller doesn't hold active lease of element
+var ErrInvalidLease = errors.New("lease is not valid")
+
+// ErrLeased returned by operations which give element new key while consumer holds its lease.
+// Lease holder refers to element by key, so re-keyed element can't be acknowledged
+var ErrLeased = errors.New("element is leased")
+
+// Lease of received element. While lease active element is parked and hidden from other consumers
+type Lease struct {
+	ID    string    `json:"id"`
+	Owner string    `json:"owner"`
+	Until time.Time `json:"until"`
+}
+
+// Active report whether lease still holds element at provided time
+func (l *Lease) Active(now time.Time) bool {
+	return l != nil && now.Before(l.Until)
+}
+
+// checkNotLeased return ErrLeased when element has active lease
+func checkNotLeased(l *list, key string, now time.Time) error {
+	e, err := l.get(key)
+	if err != nil {
+		return err
+	}
+
+	if e != nil && e.Lease.Active(now) {
+		return fmt.Errorf("key %q leased till %s: %w", key, e.Lease.Until.Format(time.RFC3339Nano), ErrLeased)
+	}
+
+	return nil
+}
+
+// clientID identify caller of transaction across all MSPs
+func clientID(ctx TransactionContextInterface) (string, error) {
+	ci := ctx.GetClientIdentity()
+	if ci == nil {
+		return "", fmt.Errorf("client identity is not available")
+	}
+
+	msp, err := ci.GetMSPID()
+	if err != nil {
+		return "", fmt.Errorf("get client msp error: %w", err)
+	}
+
+	id, err := ci.GetID()
+	if err != nil {
+		return "", fmt.Errorf("get client id error: %w", err)
+	}
+
+	return msp + "/" + id, nil
+}
+
+// Receive lease up to n elements from the head of the queue to calling client. Scheduled and expired elements are skipped.
+// Leased elements are parked out of queue links, so they are hidden from other consumers and don't block the head
+// until visibilityTimeout (Go duration: 30s, 5m) passed. Then they are linked back at the place of their keys.
+// Every element should be confirmed with Ack or returned with Nack using provided lease ID.
+// Element which was delivered max_receives times moved into dead-letter queue instead of delivery
+func (s *SimpleQueueContract) Receive(ctx TransactionContextInterface, queue string, n int, visibilityTimeout string) (res []Query, err error) {
+	timeout, err := time.ParseDuration(visibilityTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("parse visibility timeout error: %w", err)
+	}
+
+	if timeout <= 0 {
+		return nil, fmt.Errorf("visibility timeout should be positive")
+	}
+
+	owner, err := clientID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return nil, err
+	}
+
+	if err = checkBatch(l, n); err != nil {
+		return nil, err
+	}
+
+	dlq := &deadLetters{ctx: ctx, src: l}
+
+	for key, skipped := l.meta.Head, 0; key != "" && len(res) < n; {
+		if err = checkWalk(l, skipped); err != nil {
+			// keep elements moved into dead-letter queue
+			if len(res) > 0 || dlq.dst != nil {
+				break
+			}
+
+			return nil, err
+		}
+
+		e, err := l.mustGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		next := e.Next
+
+		switch {
+		case !e.Visible(now), e.Expired(now), e.Lease.Active(now):
+			skipped++
+		case l.meta.Config.MaxReceives > 0 && e.Receives >= l.meta.Config.MaxReceives:
+			skipped++
+
+			reason := fmt.Sprintf("max receives %d exceeded", l.meta.Config.MaxReceives)
+			if err = dlq.move(key, reason); err != nil {
+				return nil, err
+			}
+		default:
+			e.Receives++
+			e.Lease = &Lease{
+				ID:    TxSuffix(ctx.GetStub().GetTxID(), ctx.Sequence()),
+				Owner: owner,
+				Until: now.Add(timeout),
+			}
+
+			if err = l.park(key, e, e.Lease.Until); err != nil {
+				return nil, err
+			}
+
+			res = append(res, Query{key, e.SimpleQueue})
+		}
+
+		key = next
+	}
+
+	if err = dlq.save(); err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Ack confirm processing of received element and delete it
+func (s *SimpleQueueContract) Ack(ctx TransactionContextInterface, queue, key, leaseID string) error {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return err
+	}
+
+	if _, err = leased(ctx, l, key, leaseID); err != nil {
+		return err
+	}
+
+	if _, err = l.remove(key); err != nil {
+		return err
+	}
+
+	return l.save()
+}
+
+// Nack release received element, so it's immediately linked back and visible to receivers again
+func (s *SimpleQueueContract) Nack(ctx TransactionContextInterface, queue, key, leaseID string) error {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return err
+	}
+
+	if err = l.allow(accessPop); err != nil {
+		return err
+	}
+
+	e, err := leased(ctx, l, key, leaseID)
+	if err != nil {
+		return err
+	}
+
+	e.Lease = nil
+	if err = l.unpark(key, e); err != nil {
+		return err
+	}
+
+	return l.save()
+}
+
+// leased return element which active lease is held by caller
+func leased(ctx TransactionContextInterface, l *list, key, leaseID string) (*entry, error) {
+	owner, err := clientID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	e, err := l.get(key)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("error extracting object with provided key: %w", err)
+	case e == nil:
+		return nil, fmt.Errorf("asset with key %s not exists", key)
+	case e.Lease == nil || e.Lease.ID != leaseID:
+		return nil, fmt.Errorf("key %q lease %q not found: %w", key, leaseID, ErrInvalidLease)
+	case e.Lease.Owner != owner:
+		return nil, fmt.Errorf("key %q lease %q belongs to other client: %w", key, leaseID, ErrInvalidLease)
+	case !e.Lease.Active(now):
+		return nil, fmt.Errorf("key %q lease %q expired at %s: %w", key, leaseID, e.Lease.Until.Format(time.RFC3339Nano), ErrInvalidLease)
+	}
+
+	return e, nil
+}
@@ -0,0 +1,228 @@
+// +build unit
+
+package leveldb
+
+import (
+	"crypto/x509"
+	"errors"
+	"strings"
+	"time"
+)
+
+// identity fake client identity
+type identity struct {
+	msp, id string
+}
+
+func (i identity) GetID() (string, error) { return i.id, nil }
+
+func (i identity) GetMSPID() (string, error) { return i.msp, nil }
+
+func (i identity) GetAttributeValue(string) (string, bool, error) { return "", false, nil }
+
+func (i identity) AssertAttributeValue(string, string) error { return nil }
+
+func (i identity) GetX509Certificate() (*x509.Certificate, error) { return nil, nil }
+
+// clientCtx context of client with pinned time
+func (s *Suite) clientCtx(id string, now time.Time) *TransactionContext {
+	ctx := &TransactionContext{Clock: FixedClock(now)}
+	ctx.SetStub(s.stub)
+	ctx.SetClientIdentity(identity{"Org1MSP", id})
+
+	return ctx
+}
+
+func (s *Suite) TestLease() {
+	const queue = "lease"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	alice, bob := s.clientCtx("alice", now), s.clientCtx("bob", now)
+
+	_, err := s.contract.CreateQueue(s.ctx, queue)
+	s.NoError(err)
+
+	var pushed []*Query
+	for i := 0; i < 3; i++ {
+		q, err := s.contract.PushBack(alice, queue, `{}`)
+		s.NoError(err)
+
+		pushed = append(pushed, q)
+	}
+
+	_, err = s.contract.Receive(s.ctx, queue, 1, "1m")
+	s.Error(err, "no client identity")
+
+	_, err = s.contract.Receive(alice, queue, 0, "1m")
+	s.Error(err)
+
+	_, err = s.contract.Receive(alice, queue, 1, "-1m")
+	s.Error(err)
+
+	a, err := s.contract.Receive(alice, queue, 2, "1m")
+	s.NoError(err)
+	s.Len(a, 2)
+	s.Equal(pushed[0].Key, a[0].Key)
+	s.Equal(pushed[1].Key, a[1].Key)
+	s.Equal("Org1MSP/alice", a[0].Object.Lease.Owner)
+	s.True(now.Add(time.Minute).Equal(a[0].Object.Lease.Until))
+	s.NotEqual(a[0].Object.Lease.ID, a[1].Object.Lease.ID)
+
+	// leased elements are hidden from other receivers
+	b, err := s.contract.Receive(bob, queue, 2, "1m")
+	s.NoError(err)
+	s.Len(b, 1)
+	s.Equal(pushed[2].Key, b[0].Key)
+
+	b2, err := s.contract.Receive(bob, queue, 1, "1m")
+	s.NoError(err)
+	s.Empty(b2)
+
+	s.Run("Ack", func() {
+		err := s.contract.Ack(bob, queue, a[0].Key, a[0].Object.Lease.ID)
+		s.True(errors.Is(err, ErrInvalidLease), "other client")
+
+		err = s.contract.Ack(alice, queue, a[0].Key, b[0].Object.Lease.ID)
+		s.True(errors.Is(err, ErrInvalidLease), "other lease")
+
+		s.NoError(s.contract.Ack(alice, queue, a[0].Key, a[0].Object.Lease.ID))
+
+		_, err = s.contract.Get(alice, queue, a[0].Key)
+		s.Error(err)
+	})
+
+	s.Run("Nack", func() {
+		s.NoError(s.contract.Nack(bob, queue, b[0].Key, b[0].Object.Lease.ID))
+
+		q, err := s.contract.Get(bob, queue, b[0].Key)
+		s.NoError(err)
+		s.Nil(q.Object.Lease)
+
+		err = s.contract.Nack(bob, queue, b[0].Key, b[0].Object.Lease.ID)
+		s.True(errors.Is(err, ErrInvalidLease))
+
+		// released element visible again
+		res, err := s.contract.Receive(bob, queue, 2, "1m")
+		s.NoError(err)
+		s.Len(res, 1)
+		s.Equal(b[0].Key, res[0].Key)
+
+		b = res
+	})
+
+	s.Run("expired", func() {
+		later := s.clientCtx("bob", now.Add(time.Minute))
+
+		err := s.contract.Ack(s.clientCtx("alice", now.Add(time.Minute)), queue, a[1].Key, a[1].Object.Lease.ID)
+		s.True(errors.Is(err, ErrInvalidLease))
+
+		// both leases passed, elements delivered again
+		res, err := s.contract.Receive(later, queue, 5, "1m")
+		s.NoError(err)
+		s.Len(res, 2)
+		s.Equal(a[1].Key, res[0].Key)
+		s.Equal(b[0].Key, res[1].Key)
+
+		for _, q := range res {
+			s.NoError(s.contract.Ack(later, queue, q.Key, q.Object.Lease.ID))
+		}
+	})
+
+	s.Run("pop", func() {
+		first, err := s.contract.PushBack(alice, queue, `{}`)
+		s.NoError(err)
+
+		second, err := s.contract.PushBack(alice, queue, `{}`)
+		s.NoError(err)
+
+		res, err := s.contract.Receive(alice, queue, 1, "1m")
+		s.NoError(err)
+		s.Equal(first.Key, res[0].Key)
+
+		// leased element can't be taken by other consumer
+		q, err := s.contract.Front(bob, queue)
+		s.NoError(err)
+		s.Equal(second.Key, q.Key)
+
+		q, err = s.contract.PopFront(bob, queue)
+		s.NoError(err)
+		s.Equal(second.Key, q.Key)
+
+		_, err = s.contract.PopFront(bob, queue)
+		s.True(errors.Is(err, ErrEmptyQueue))
+
+		_, err = s.contract.PopBack(bob, queue)
+		s.True(errors.Is(err, ErrEmptyQueue))
+
+		n, err := s.contract.PopFrontN(bob, queue, 2)
+		s.NoError(err)
+		s.Empty(n)
+
+		n, err = s.contract.PopN(bob, queue, 2)
+		s.NoError(err)
+		s.Empty(n)
+
+		s.NoError(s.contract.Ack(alice, queue, first.Key, res[0].Object.Lease.ID))
+	})
+
+	s.Run("parked", func() {
+		const queue = "lease-parked"
+
+		_, err := s.contract.CreateQueue(s.ctx, queue)
+		s.NoError(err)
+
+		var contexts []string
+		for i := 0; i < 50; i++ {
+			contexts = append(contexts, `{}`)
+		}
+
+		var pushed []Query
+		for i := 0; i < 3; i++ {
+			res, err := s.contract.PushBackBatch(alice, queue, "["+strings.Join(contexts, ",")+"]")
+			s.NoError(err)
+
+			pushed = append(pushed, res...)
+		}
+
+		_, err = s.contract.Receive(alice, queue, DefaultMaxBatch+1, "1m")
+		s.Error(err)
+
+		a, err := s.contract.Receive(alice, queue, DefaultMaxBatch, "1m")
+		s.NoError(err)
+		s.Len(a, DefaultMaxBatch)
+
+		// leased elements are out of links, other consumers don't walk over them
+		b, err := s.contract.Receive(bob, queue, 1, "1m")
+		s.NoError(err)
+		s.Len(b, 1)
+		s.Equal(pushed[100].Key, b[0].Key)
+
+		q, err := s.contract.Front(bob, queue)
+		s.NoError(err)
+		s.Equal(pushed[101].Key, q.Key)
+
+		q, err = s.contract.PopFront(bob, queue)
+		s.NoError(err)
+		s.Equal(pushed[101].Key, q.Key)
+
+		// released element returns to its place
+		s.NoError(s.contract.Nack(alice, queue, a[1].Key, a[1].Object.Lease.ID))
+
+		q, err = s.contract.Front(bob, queue)
+		s.NoError(err)
+		s.Equal(a[1].Key, q.Key)
+
+		stats, err := s.contract.Stats(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(149, stats.Length)
+
+		s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+	})
+
+	stats, err := s.contract.Stats(s.ctx, queue)
+	s.NoError(err)
+	s.Zero(stats.Length)
+	s.Zero(stats.Bytes)
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+}
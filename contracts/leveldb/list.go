@@ -0,0 +1,652 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+const (
+	// queueObjectType composite key object type of named queue metadata: queue~name
+	queueObjectType = "queue"
+
+	// itemObjectType composite key object type of named queue element: item~queue~key
+	itemObjectType = "item"
+
+	// legacyObjectType composite key object type of metadata of single flat queue which was used before named queues
+	legacyObjectType = "meta"
+
+	// dedupObjectType composite key object type of deduplication record: dedup~queue~id
+	dedupObjectType = "dedup"
+
+	// groupObjectType composite key object type of consumer group record: group~queue~name
+	groupObjectType = "group"
+
+	// parkObjectType composite key object type of parked element record: park~queue~time~key
+	parkObjectType = "park"
+)
+
+// firstKey is the smallest simple key. Peer uses it instead of empty start key of range extraction
+const firstKey = "\x01"
+
+// lastKey is greater than any queue key
+const lastKey = string(utf8.MaxRune)
+
+// ErrQueueNotFound returned when operation performed with queue which wasn't created
+var ErrQueueNotFound = errors.New("queue not found")
+
+// QueueMeta describe named queue.
+// Bounds allow access edge elements via point read without range scan, counters are maintained by every mutation
+type QueueMeta struct {
+	Name   string `json:"name"`
+	Head   string `json:"head"`
+	Tail   string `json:"tail"`
+	Length int    `json:"length"`
+
+	// Parked amount of elements which are out of links till some time, they are counted by Length too
+	Parked int `json:"parked,omitempty" metadata:"parked,optional"`
+
+	// Bytes total size of stored elements
+	Bytes int `json:"bytes"`
+
+	Config QueueConfig `json:"config"`
+
+	// State administrative state of queue, QueueStateActive when empty
+	State string `json:"state,omitempty" metadata:"state,optional"`
+}
+
+// entry is ledger representation of queue element.
+// Besides element itself it contain links to neighbours which allow to walk queue in both directions.
+type entry struct {
+	SimpleQueue
+
+	Prev string `json:"prev,omitempty"`
+	Next string `json:"next,omitempty"`
+
+	// Parked time attribute of park record, empty for linked entry
+	Parked string `json:"parked,omitempty"`
+}
+
+// list is doubly linked list of queue elements stored in the ledger.
+// Fabric doesn't read own writes inside transaction, so all touched entries cached and list should be opened
+// only once per transaction.
+type list struct {
+	stub    shim.ChaincodeStubInterface
+	metaKey string
+	meta    QueueMeta
+
+	// nil value marks removed entry
+	entries map[string]*entry
+
+	// stored size of entries which were read or written
+	sizes map[string]int
+
+	// deferred keeps writes of settle in cache, they are done by save together with deletion of park records
+	deferred bool
+	dirty    []string
+	unparked []string
+}
+
+// openList read metadata of existent named queue
+func openList(stub shim.ChaincodeStubInterface, name string) (*list, error) {
+	l, ok, err := loadList(stub, queueObjectType, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		if name == DefaultQueue && !migrated(stub) {
+			return nil, fmt.Errorf("queue %q: %w, flat queue elements should be moved by MigrateKeys", name, ErrQueueNotFound)
+		}
+
+		return nil, fmt.Errorf("queue %q: %w", name, ErrQueueNotFound)
+	}
+
+	return l, nil
+}
+
+// openQueue read metadata of existent named queue and link back parked elements which time came
+func openQueue(ctx TransactionContextInterface, name string) (*list, error) {
+	l, err := openList(ctx.GetStub(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.settle(now); err != nil {
+		return nil, err
+	}
+
+	return l, nil
+}
+
+// migrated report whether flat queue used before named queues has no elements left for MigrateKeys.
+// Read errors are treated as migrated, it's used only to explain missing elements
+func migrated(stub shim.ChaincodeStubInterface) bool {
+	src, err := openLegacyList(stub)
+	if err != nil || src.meta.Length > 0 {
+		return err != nil
+	}
+
+	itr, err := stub.GetStateByRange(firstKey, KeyV2Prefix)
+	if err != nil {
+		return true
+	}
+
+	defer itr.Close()
+
+	return !itr.HasNext()
+}
+
+// errEmpty return ErrEmptyQueue. Empty DefaultQueue also reports flat queue elements which weren't migrated
+func (l *list) errEmpty() error {
+	if l.meta.Name == DefaultQueue && !migrated(l.stub) {
+		return fmt.Errorf("%w, flat queue elements should be moved by MigrateKeys", ErrEmptyQueue)
+	}
+
+	return ErrEmptyQueue
+}
+
+// openLegacyList read metadata of flat queue which keys are not namespaced. Absent metadata means empty queue
+func openLegacyList(stub shim.ChaincodeStubInterface) (*list, error) {
+	l, _, err := loadList(stub, legacyObjectType, "")
+
+	return l, err
+}
+
+// unlinkedKeys return up to limit keys of flat queue elements written before queue metadata was introduced,
+// ordered by time. Such elements have TimedKey keys without links. Seconds of TimedKey have fixed width, but
+// nanoseconds are not padded, so 1589702933-9 is sorted after 1589702933-10: keys are read in string order and
+// only elements of the same second are sorted. Reading stops at the first second after limit is reached.
+// Linked elements of legacy list are skipped, head is provided by caller
+func unlinkedKeys(stub shim.ChaincodeStubInterface, head string, limit int) ([]string, error) {
+	itr, err := stub.GetStateByRange(firstKey, KeyV2Prefix)
+	if err != nil {
+		return nil, fmt.Errorf("can't get range state: %w", err)
+	}
+
+	defer itr.Close()
+
+	type timedKey struct {
+		key string
+		t   time.Time
+	}
+
+	var (
+		res    []string
+		second string
+		group  []timedKey
+	)
+
+	// flush append keys of the same second ordered by time
+	flush := func() {
+		sort.Slice(group, func(i, j int) bool {
+			return group[i].t.Before(group[j].t)
+		})
+
+		for _, k := range group {
+			res = append(res, k.key)
+		}
+
+		group = group[:0]
+	}
+
+	for itr.HasNext() {
+		i, err := itr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("next result error: %w", err)
+		}
+
+		e := &entry{}
+		if err = json.Unmarshal(i.Value, e); err != nil {
+			return nil, fmt.Errorf("unmarshal key %q error: %w", i.Key, err)
+		}
+
+		if i.Key == head || e.Prev != "" || e.Next != "" {
+			continue
+		}
+
+		t, err := ParseKey(i.Key)
+		if err != nil {
+			return nil, err
+		}
+
+		if s := strings.SplitN(i.Key, "-", 2)[0]; s != second {
+			flush()
+
+			if len(res) >= limit {
+				break
+			}
+
+			second = s
+		}
+
+		group = append(group, timedKey{i.Key, t})
+	}
+
+	flush()
+
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+// createList initialize metadata of new named queue
+func createList(stub shim.ChaincodeStubInterface, name string) (*list, error) {
+	if name == "" {
+		return nil, fmt.Errorf("queue name should not be empty")
+	}
+
+	l, ok, err := loadList(stub, queueObjectType, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		return nil, fmt.Errorf("queue %q already exists", name)
+	}
+
+	return l, nil
+}
+
+func loadList(stub shim.ChaincodeStubInterface, objectType, name string) (*list, bool, error) {
+	var attributes []string
+	if name != "" {
+		attributes = append(attributes, name)
+	}
+
+	key, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, false, fmt.Errorf("create meta key error: %w", err)
+	}
+
+	l := &list{stub: stub, metaKey: key, meta: QueueMeta{Name: name}, entries: make(map[string]*entry), sizes: make(map[string]int)}
+
+	v, err := stub.GetState(key)
+	if err != nil {
+		return nil, false, fmt.Errorf("read queue meta error: %w", err)
+	}
+
+	if v == nil {
+		return l, false, nil
+	}
+
+	if err = json.Unmarshal(v, &l.meta); err != nil {
+		return nil, false, fmt.Errorf("unmarshal queue meta error: %w", err)
+	}
+
+	return l, true, nil
+}
+
+// key return ledger key of queue element. Elements of legacy flat queue use simple keys
+func (l *list) key(key string) (string, error) {
+	if l.meta.Name == "" {
+		return key, nil
+	}
+
+	k, err := l.stub.CreateCompositeKey(itemObjectType, []string{l.meta.Name, key})
+	if err != nil {
+		return "", fmt.Errorf("create key of %q error: %w", key, err)
+	}
+
+	return k, nil
+}
+
+// save write queue metadata and changes deferred by settle
+func (l *list) save() error {
+	for _, key := range l.dirty {
+		if e := l.entries[key]; e != nil {
+			if err := l.put(key, e); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, key := range l.unparked {
+		if err := l.stub.DelState(key); err != nil {
+			return fmt.Errorf("delete park record error: %w", err)
+		}
+	}
+
+	l.dirty, l.unparked = nil, nil
+
+	blob, err := json.Marshal(&l.meta)
+	if err != nil {
+		return fmt.Errorf("marshal queue meta error: %w", err)
+	}
+
+	if err = l.stub.PutState(l.metaKey, blob); err != nil {
+		return fmt.Errorf("write queue meta error: %w", err)
+	}
+
+	return nil
+}
+
+// get return entry by key or nil if it not exists
+func (l *list) get(key string) (*entry, error) {
+	if e, ok := l.entries[key]; ok {
+		return e, nil
+	}
+
+	k, err := l.key(key)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := l.stub.GetState(k)
+	if err != nil {
+		return nil, fmt.Errorf("read key %q error: %w", key, err)
+	}
+
+	if v == nil {
+		return nil, nil
+	}
+
+	e := &entry{}
+	if err = json.Unmarshal(v, e); err != nil {
+		return nil, fmt.Errorf("unmarshal key %q error: %w", key, err)
+	}
+
+	l.entries[key] = e
+	l.sizes[key] = len(v)
+
+	return e, nil
+}
+
+// mustGet same as get but absent entry is an error. Used for entries referenced by the list itself
+func (l *list) mustGet(key string) (*entry, error) {
+	e, err := l.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("queue is broken: linked key %q not exists", key)
+	}
+
+	return e, nil
+}
+
+// put write entry as is. Existent entry should be read before, otherwise size counter drifts
+func (l *list) put(key string, e *entry) error {
+	k, err := l.key(key)
+	if err != nil {
+		return err
+	}
+
+	blob, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("marshal key %q error: %w", key, err)
+	}
+
+	if l.deferred {
+		l.dirty = append(l.dirty, key)
+	} else if err = l.stub.PutState(k, blob); err != nil {
+		return fmt.Errorf("write key %q error: %w", key, err)
+	}
+
+	l.entries[key] = e
+	l.meta.Bytes += len(blob) - l.sizes[key]
+	l.sizes[key] = len(blob)
+
+	return nil
+}
+
+// del delete entry as is
+func (l *list) del(key string) error {
+	k, err := l.key(key)
+	if err != nil {
+		return err
+	}
+
+	if err = l.stub.DelState(k); err != nil {
+		return fmt.Errorf("delete key %q error: %w", key, err)
+	}
+
+	l.entries[key] = nil
+	l.meta.Bytes -= l.sizes[key]
+	l.sizes[key] = 0
+
+	return nil
+}
+
+// pushBack link new entry after the tail
+func (l *list) pushBack(key string, e *entry) error {
+	return l.insertAfter(l.meta.Tail, key, e)
+}
+
+// insertAfter link new entry right after prev. Empty prev means insertion before the head
+func (l *list) insertAfter(prev, key string, e *entry) error {
+	l.meta.Length++
+
+	return l.link(prev, key, e)
+}
+
+// link entry right after prev and write it. Empty prev means the head
+func (l *list) link(prev, key string, e *entry) error {
+	next := l.meta.Head
+
+	if prev != "" {
+		p, err := l.mustGet(prev)
+		if err != nil {
+			return err
+		}
+
+		next, p.Next = p.Next, key
+		if err = l.put(prev, p); err != nil {
+			return err
+		}
+	} else {
+		l.meta.Head = key
+	}
+
+	if next != "" {
+		n, err := l.mustGet(next)
+		if err != nil {
+			return err
+		}
+
+		n.Prev = key
+		if err = l.put(next, n); err != nil {
+			return err
+		}
+	} else {
+		l.meta.Tail = key
+	}
+
+	e.Prev, e.Next = prev, next
+
+	return l.put(key, e)
+}
+
+// unlink detach entry from neighbours, entry itself is not written
+func (l *list) unlink(e *entry) error {
+	if e.Prev != "" {
+		prev, err := l.mustGet(e.Prev)
+		if err != nil {
+			return err
+		}
+
+		prev.Next = e.Next
+		if err = l.put(e.Prev, prev); err != nil {
+			return err
+		}
+	} else {
+		l.meta.Head = e.Next
+	}
+
+	if e.Next != "" {
+		next, err := l.mustGet(e.Next)
+		if err != nil {
+			return err
+		}
+
+		next.Prev = e.Prev
+		if err = l.put(e.Next, next); err != nil {
+			return err
+		}
+	} else {
+		l.meta.Tail = e.Prev
+	}
+
+	e.Prev, e.Next = "", ""
+
+	return nil
+}
+
+// remove unlink entry from the queue and delete it
+func (l *list) remove(key string) (*entry, error) {
+	e, err := l.get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if e == nil {
+		return nil, fmt.Errorf("asset with key %s not exists", key)
+	}
+
+	if e.Parked != "" {
+		err = l.dropPark(key, e)
+	} else {
+		err = l.unlink(e)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	l.meta.Length--
+
+	if err = l.del(key); err != nil {
+		return nil, err
+	}
+
+	return e, nil
+}
+
+// scan iterate elements of named queue in key order. Iteration stops when fn returns false.
+// Entries changed by current transaction are taken from cache, new ones are not visible
+func (l *list) scan(fn func(key string, e *entry) (bool, error)) error {
+	itr, err := l.stub.GetStateByPartialCompositeKey(itemObjectType, []string{l.meta.Name})
+	if err != nil {
+		return fmt.Errorf("can't get range state")
+	}
+
+	defer itr.Close()
+
+	for itr.HasNext() {
+		i, err := itr.Next()
+		if err != nil {
+			return fmt.Errorf("next result error: %w", err)
+		}
+
+		_, attributes, err := l.stub.SplitCompositeKey(i.Key)
+		if err != nil {
+			return fmt.Errorf("split key error: %w", err)
+		}
+
+		key := attributes[1]
+
+		e, ok := l.entries[key]
+		if !ok {
+			e = &entry{}
+			if err = json.Unmarshal(i.Value, e); err != nil {
+				return fmt.Errorf("unmarshal error: %w", err)
+			}
+
+			l.sizes[key] = len(i.Value)
+		}
+
+		if e == nil {
+			continue
+		}
+
+		next, err := fn(key, e)
+		if err != nil || !next {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drop delete all elements, indexes and metadata of the queue
+func (l *list) drop() error {
+	var keys []string
+
+	err := l.scan(func(key string, _ *entry) (bool, error) {
+		keys = append(keys, key)
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err = l.del(key); err != nil {
+			return err
+		}
+	}
+
+	if err = l.dropIndex(dedupObjectType); err != nil {
+		return err
+	}
+
+	if err = l.dropIndex(groupObjectType); err != nil {
+		return err
+	}
+
+	if err = l.dropIndex(parkObjectType); err != nil {
+		return err
+	}
+
+	if err = l.stub.DelState(l.metaKey); err != nil {
+		return fmt.Errorf("delete queue meta error: %w", err)
+	}
+
+	return nil
+}
+
+// dropIndex delete all records of queue with provided object type
+func (l *list) dropIndex(objectType string) error {
+	itr, err := l.stub.GetStateByPartialCompositeKey(objectType, []string{l.meta.Name})
+	if err != nil {
+		return fmt.Errorf("can't get range state")
+	}
+
+	var keys []string
+
+	for itr.HasNext() {
+		i, err := itr.Next()
+		if err != nil {
+			_ = itr.Close()
+			return fmt.Errorf("next result error: %w", err)
+		}
+
+		keys = append(keys, i.Key)
+	}
+
+	if err = itr.Close(); err != nil {
+		return fmt.Errorf("close iterator error: %w", err)
+	}
+
+	for _, key := range keys {
+		if err = l.stub.DelState(key); err != nil {
+			return fmt.Errorf("delete %s record error: %w", objectType, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,215 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList(t *testing.T) {
+	stub := shimtest.NewMockStub("list", new(SimpleChaincode))
+	stub.MockTransactionStart("list")
+
+	_, err := openList(stub, "list")
+	assert.True(t, errors.Is(err, ErrQueueNotFound))
+
+	l, err := createList(stub, "list")
+	require.NoError(t, err)
+	assert.Equal(t, QueueMeta{Name: "list"}, l.meta)
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, l.pushBack(key, &entry{SimpleQueue: NewSimpleQueue(time.Time{})}))
+	}
+
+	require.NoError(t, l.save())
+
+	// walk queue in both directions after every removal
+	check := func(want ...string) {
+		l, err := openList(stub, "list")
+		require.NoError(t, err)
+		require.Equal(t, len(want), l.meta.Length)
+
+		var forward, backward []string
+
+		for key := l.meta.Head; key != ""; {
+			e, err := l.mustGet(key)
+			require.NoError(t, err)
+
+			forward = append(forward, key)
+			key = e.Next
+		}
+
+		for key := l.meta.Tail; key != ""; {
+			e, err := l.mustGet(key)
+			require.NoError(t, err)
+
+			backward = append([]string{key}, backward...)
+			key = e.Prev
+		}
+
+		assert.Equal(t, want, forward)
+		assert.Equal(t, want, backward)
+	}
+
+	check("a", "b", "c", "d")
+
+	for _, tt := range []struct {
+		remove string
+		want   []string
+	}{
+		{"b", []string{"a", "c", "d"}},
+		{"a", []string{"c", "d"}},
+		{"d", []string{"c"}},
+		{"c", nil},
+	} {
+		l, err := openList(stub, "list")
+		require.NoError(t, err)
+
+		e, err := l.remove(tt.remove)
+		require.NoError(t, err)
+		require.NotNil(t, e)
+		require.NoError(t, l.save())
+
+		check(tt.want...)
+	}
+
+	l, err = openList(stub, "list")
+	require.NoError(t, err)
+
+	_, err = l.remove("a")
+	assert.Error(t, err)
+
+	// elements of other queue are not visible
+	other, err := createList(stub, "other")
+	require.NoError(t, err)
+	require.NoError(t, other.pushBack("a", &entry{SimpleQueue: NewSimpleQueue(time.Time{})}))
+	require.NoError(t, other.save())
+
+	l, err = openList(stub, "list")
+	require.NoError(t, err)
+
+	e, err := l.get("a")
+	require.NoError(t, err)
+	assert.Nil(t, e)
+}
+
+func TestListInsertAfter(t *testing.T) {
+	stub := shimtest.NewMockStub("list", new(SimpleChaincode))
+	stub.MockTransactionStart("insert")
+
+	l, err := createList(stub, "insert")
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		after, key string
+	}{
+		{"", "c"},  // empty list
+		{"c", "e"}, // after tail
+		{"", "a"},  // before head
+		{"c", "d"}, // middle
+		{"a", "b"},
+	} {
+		require.NoError(t, l.insertAfter(tt.after, tt.key, &entry{SimpleQueue: NewSimpleQueue(time.Time{})}))
+	}
+
+	require.NoError(t, l.save())
+
+	l, err = openList(stub, "insert")
+	require.NoError(t, err)
+	assert.Equal(t, 5, l.meta.Length)
+
+	var forward, backward []string
+
+	for key := l.meta.Head; key != ""; {
+		e, err := l.mustGet(key)
+		require.NoError(t, err)
+
+		forward = append(forward, key)
+		key = e.Next
+	}
+
+	for key := l.meta.Tail; key != ""; {
+		e, err := l.mustGet(key)
+		require.NoError(t, err)
+
+		backward = append([]string{key}, backward...)
+		key = e.Prev
+	}
+
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, forward)
+	assert.Equal(t, forward, backward)
+}
+
+func TestListPark(t *testing.T) {
+	stub := shimtest.NewMockStub("list", new(SimpleChaincode))
+	stub.MockTransactionStart("park")
+
+	l, err := createList(stub, "park")
+	require.NoError(t, err)
+
+	for _, key := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, l.pushBack(key, &entry{SimpleQueue: NewSimpleQueue(time.Time{})}))
+	}
+
+	now := time.Now()
+
+	// parked elements are linked back in order of their time
+	for key, until := range map[string]time.Time{"a": now.Add(time.Minute), "b": now, "d": now} {
+		e, err := l.mustGet(key)
+		require.NoError(t, err)
+		require.NoError(t, l.park(key, e, until))
+	}
+
+	require.NoError(t, l.save())
+
+	forward := func() (res []string) {
+		l, err := openList(stub, "park")
+		require.NoError(t, err)
+
+		for key := l.meta.Head; key != ""; {
+			e, err := l.mustGet(key)
+			require.NoError(t, err)
+
+			res = append(res, key)
+			key = e.Next
+		}
+
+		return res
+	}
+
+	assert.Equal(t, []string{"c", "e"}, forward())
+
+	l, err = openList(stub, "park")
+	require.NoError(t, err)
+	assert.Equal(t, 5, l.meta.Length)
+	assert.Equal(t, 3, l.meta.Parked)
+
+	// settled elements are written only by save
+	require.NoError(t, l.settle(now))
+	assert.Equal(t, 1, l.meta.Parked)
+	assert.Equal(t, []string{"c", "e"}, forward())
+
+	require.NoError(t, l.save())
+	assert.Equal(t, []string{"b", "c", "d", "e"}, forward())
+
+	l, err = openList(stub, "park")
+	require.NoError(t, err)
+	require.NoError(t, l.settle(now.Add(time.Minute)))
+	require.NoError(t, l.save())
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, forward())
+
+	l, err = openList(stub, "park")
+	require.NoError(t, err)
+	assert.Equal(t, 0, l.meta.Parked)
+
+	itr, err := stub.GetStateByPartialCompositeKey(parkObjectType, []string{"park"})
+	require.NoError(t, err)
+	assert.False(t, itr.HasNext())
+	require.NoError(t, itr.Close())
+}
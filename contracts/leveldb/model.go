@@ -12,6 +12,46 @@ import (
 
 type Context map[string]interface{}
 
+// Lookup return value of field by path. Path is a top-level key or dot separated keys of nested objects
+// and indexes of arrays, e.g. address.country or items.0.sku. Top-level key containing dots wins
+func (c Context) Lookup(path string) (interface{}, bool) {
+	if v, ok := c[path]; ok {
+		return v, true
+	}
+
+	var v interface{} = map[string]interface{}(c)
+
+	for _, part := range strings.Split(path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			next, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+
+			v = next
+		case Context:
+			next, ok := node[part]
+			if !ok {
+				return nil, false
+			}
+
+			v = next
+		case []interface{}:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, false
+			}
+
+			v = node[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
+
 // SimpleQueue uses time as key identification for queue
 // support only valid JSON extra-context
 type SimpleQueue struct {
@@ -19,11 +59,51 @@ type SimpleQueue struct {
 	Time time.Time `json:"created_at"`
 
 	Context Context `json:"context"`
+
+	// Schedule present when element visibility restricted by time
+	Schedule *Schedule `json:"schedule,omitempty" metadata:"schedule,optional"`
+
+	// Expiry present when element has limited lifetime
+	Expiry *Expiry `json:"expiry,omitempty" metadata:"expiry,optional"`
+
+	// Lease present while element is received by consumer and not acknowledged
+	Lease *Lease `json:"lease,omitempty" metadata:"lease,optional"`
+
+	// Priority of element in priority mode queue. Higher priority elements are placed before older ones
+	Priority int `json:"priority,omitempty" metadata:"priority,optional"`
+
+	// Receives amount of deliveries via Receive
+	Receives int `json:"receives,omitempty" metadata:"receives,optional"`
+
+	// DeadLetter present when element moved to dead-letter queue
+	DeadLetter *DeadLetter `json:"dead_letter,omitempty" metadata:"dead_letter,optional"`
 }
 
-// NewSimpleQueue create empty queue element
-func NewSimpleQueue() SimpleQueue {
-	return SimpleQueue{Time: time.Now(), Context: make(Context)}
+// NewSimpleQueue create empty queue element created at provided time
+func NewSimpleQueue(t time.Time) SimpleQueue {
+	return SimpleQueue{Time: t, Context: make(Context)}
+}
+
+// Schedule visibility restriction of element
+type Schedule struct {
+	// NotBefore element hidden from consumers till this time
+	NotBefore time.Time `json:"not_before"`
+}
+
+// Expiry lifetime restriction of element
+type Expiry struct {
+	// At element treated as deleted since this time
+	At time.Time `json:"at"`
+}
+
+// Expired report whether element lifetime passed
+func (s *SimpleQueue) Expired(now time.Time) bool {
+	return s.Expiry != nil && !now.Before(s.Expiry.At)
+}
+
+// Visible report whether element scheduled time came
+func (s *SimpleQueue) Visible(now time.Time) bool {
+	return s.Schedule == nil || !now.Before(s.Schedule.NotBefore)
 }
 
 func (s *SimpleQueue) BLOB() ([]byte, error) {
@@ -37,80 +117,231 @@ type Query struct {
 
 type SimpleQuery []Query
 
-// Key generate unique queue value.
-// For sake of decreasing collision we use nanosecond postfix
-// But collision possible, for now it's low chance but for handling that we should make a lot more stuff
-func TimedKey(time time.Time) string {
-	return fmt.Sprintf("%d-%d", time.Unix(), time.Nanosecond())
-}
-
 // small helper
 func mustParse(value string) time.Time {
 	v, _ := time.Parse(time.RFC3339Nano, value)
 	return v
 }
 
-// Filter return filtered data.
-// Currently supported types: float64, int, string, bool
+// Live return elements which are not expired
+func (sl SimpleQuery) Live(now time.Time) (res SimpleQuery) {
+	for _, q := range sl {
+		if !q.Object.Expired(now) {
+			res = append(res, q)
+		}
+	}
+
+	return res
+}
+
+// Visible return elements which scheduled time came
+func (sl SimpleQuery) Visible(now time.Time) (res SimpleQuery) {
+	for _, q := range sl {
+		if q.Object.Visible(now) {
+			res = append(res, q)
+		}
+	}
+
+	return res
+}
+
+// Filter return filtered data. Field can be a path to nested value, see Context.Lookup. Elements without the field
+// are excluded unless operator is MISSING.
+// Currently supported types: float64, int, string, bool, null and arrays of them. Bool supports only equality operators,
+// strings which both are RFC3339 times compared as times, null equals only to FilterNull value
 // Just in case: marshaling all numbers transform into float64
 func (sl SimpleQuery) Filter(f Filter) (res SimpleQuery, err error) {
-	for i, queue := range sl {
-		v, ok := queue.Object.Context[f.Key]
-		if !ok {
-			continue
+	if err = f.validOp(); err != nil {
+		return nil, err
+	}
+
+	return sl.Where(Condition{Filter: f})
+}
+
+// Where return elements which match condition
+func (sl SimpleQuery) Where(c Condition) (res SimpleQuery, err error) {
+	for i := range sl {
+		ok, err := c.match(sl[i])
+		if err != nil {
+			return nil, err
 		}
 
-		add := false
+		if ok {
+			res = append(res, sl[i])
+		}
+	}
 
-		switch exp := v.(type) {
-		case string:
-			add = exp == f.Value
+	return res, nil
+}
 
-		case bool:
-			add = (exp && strings.ToUpper(f.Value) == "TRUE") ||
-				(!exp && strings.ToUpper(f.Value) == "FALSE")
+// matches report whether context field of element satisfies filter
+func (f Filter) matches(queue Query) (bool, error) {
+	if err := f.validOp(); err != nil {
+		return false, err
+	}
 
-		case int:
-			p, err := strconv.ParseInt(f.Value, 0, 64)
-			if err != nil {
-				return nil, fmt.Errorf("can't parse %q to float64 error: %w", f.Value, err)
+	v, ok := queue.Object.Context.Lookup(f.Key)
+
+	switch {
+	case f.Op == FilterExists:
+		return ok, nil
+	case f.Op == FilterMissing:
+		return !ok, nil
+	case !ok:
+		return false, nil
+	}
+
+	return f.value(v)
+}
+
+// value report whether context value satisfies filter. Array satisfies when any of its elements does,
+// negative operators when none does
+func (f Filter) value(v interface{}) (bool, error) {
+	switch f.Op {
+	case FilterNotEqual, FilterNotIn:
+		if f.Op == FilterNotEqual {
+			f.Op = FilterEqual
+		} else {
+			f.Op = FilterIn
+		}
+
+		ok, err := f.value(v)
+
+		return !ok && err == nil, err
+	case FilterIn:
+		for _, item := range strings.Split(f.Value, ",") {
+			ok, err := Filter{Key: f.Key, Value: item}.value(v)
+			if err != nil || ok {
+				return ok, err
 			}
+		}
 
-			add = int(p) == exp
-		case float64:
-			p, err := strconv.ParseFloat(f.Value, 64)
-			if err != nil {
-				return nil, fmt.Errorf("can't parse %q to float64 error: %w", f.Value, err)
+		return false, nil
+	}
+
+	switch exp := v.(type) {
+	case nil:
+		return f.Value == FilterNull && (f.Op == "" || f.Op == FilterEqual), nil
+
+	case []interface{}:
+		for _, item := range exp {
+			ok, err := f.value(item)
+			if err != nil || ok {
+				return ok, err
 			}
+		}
 
-			add = p == exp
-		default:
-			log.Printf("Filter unsuported type %T for key %q val %q", v, f.Key, f.Value)
-			continue
+		return false, nil
+
+	case string:
+		return f.match(compareStrings(exp, f.Value)), nil
+
+	case bool:
+		if f.Op != "" && f.Op != FilterEqual {
+			return false, fmt.Errorf("operator %q is not supported for bool field %q", f.Op, f.Key)
 		}
 
-		if add {
-			res = append(res, sl[i])
+		return (exp && strings.ToUpper(f.Value) == "TRUE") ||
+			(!exp && strings.ToUpper(f.Value) == "FALSE"), nil
+
+	case int:
+		p, err := strconv.ParseInt(f.Value, 0, 64)
+		if err != nil {
+			return false, fmt.Errorf("can't parse %q to float64 error: %w", f.Value, err)
 		}
 
+		return f.match(compareFloats(float64(exp), float64(p))), nil
+	case float64:
+		p, err := strconv.ParseFloat(f.Value, 64)
+		if err != nil {
+			return false, fmt.Errorf("can't parse %q to float64 error: %w", f.Value, err)
+		}
+
+		return f.match(compareFloats(exp, p)), nil
 	}
 
-	return res, nil
+	log.Printf("Filter unsuported type %T for key %q val %q", v, f.Key, f.Value)
+
+	return false, nil
+}
+
+// validOp check that operator of filter is known
+func (f Filter) validOp() error {
+	if f.Op == "" {
+		return nil
+	}
+
+	for _, ops := range [][]string{filterOperators, filterKeywords} {
+		for _, op := range ops {
+			if f.Op == op {
+				return nil
+			}
+		}
+	}
+
+	return fmt.Errorf("unknown filter operator %q", f.Op)
+}
+
+// match apply operator of filter to result of comparison of context value with filter value
+func (f Filter) match(c int) bool {
+	switch f.Op {
+	case FilterNotEqual:
+		return c != 0
+	case FilterGreater:
+		return c > 0
+	case FilterGreaterOrEqual:
+		return c >= 0
+	case FilterLess:
+		return c < 0
+	case FilterLessOrEqual:
+		return c <= 0
+	}
+
+	return c == 0
+}
+
+// compareFloats return -1, 0 or 1 when a less, equal or greater than b
+func compareFloats(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// compareStrings compare strings as times when both are RFC3339 times, otherwise lexicographically
+func compareStrings(a, b string) int {
+	ta, errA := time.Parse(time.RFC3339Nano, a)
+	tb, errB := time.Parse(time.RFC3339Nano, b)
+
+	switch {
+	case errA != nil || errB != nil:
+		return strings.Compare(a, b)
+	case ta.Before(tb):
+		return -1
+	case ta.After(tb):
+		return 1
+	}
+
+	return 0
 }
 
-// Sort query array
+// Sort query array. Field can be a path to nested value, elements without the field go last
 func (sl SimpleQuery) Sort(s Sort) (SimpleQuery, error) {
 	if s.Field == "" {
 		return sl, nil
 	}
 
 	sort.Slice(sl, func(i, j int) bool {
-		vi, ok := sl[i].Object.Context[s.Field]
+		vi, ok := sl[i].Object.Context.Lookup(s.Field)
 		if !ok {
 			return false
 		}
 
-		vj, ok := sl[j].Object.Context[s.Field]
+		vj, ok := sl[j].Object.Context.Lookup(s.Field)
 		if !ok {
 			return true
 		}
@@ -86,6 +86,137 @@ func TestSimpleQuery_Filter(t *testing.T) {
 			wantRes: nil,
 			wantErr: true,
 		},
+		{
+			name: "filter greater float",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"amount": 1000.0}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"amount": 1000.5}}},
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY"}}},
+			},
+			args: args{f: Filter{Key: "amount", Value: "1000", Op: FilterGreater}},
+			wantRes: []Query{
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"amount": 1000.5}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter less or equal int",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"num": 1}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"num": 2}}},
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"num": 3}}},
+			},
+			args: args{f: Filter{Key: "num", Value: "2", Op: FilterLessOrEqual}},
+			wantRes: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"num": 1}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"num": 2}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter not equal string",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY"}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"country": "RU"}}},
+			},
+			args: args{f: Filter{Key: "country", Value: "BY", Op: FilterNotEqual}},
+			wantRes: []Query{
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"country": "RU"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter greater or equal string",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY"}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"country": "RU"}}},
+			},
+			args: args{f: Filter{Key: "country", Value: "C", Op: FilterGreaterOrEqual}},
+			wantRes: []Query{
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"country": "RU"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter time",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"at": "2021-05-17T11:08:53+03:00"}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"at": "2021-05-17T09:08:53Z"}}},
+			},
+			// lexicographically "2021-05-17T11..." is greater, but it's earlier time
+			args: args{f: Filter{Key: "at", Value: "2021-05-17T08:30:00Z", Op: FilterGreater}},
+			wantRes: []Query{
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"at": "2021-05-17T09:08:53Z"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter bool not equal",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"b": true}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"b": false}}},
+			},
+			args: args{f: Filter{Key: "b", Value: "true", Op: FilterNotEqual}},
+			wantRes: []Query{
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"b": false}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter bool greater",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"b": true}}},
+			},
+			args:    args{f: Filter{Key: "b", Value: "true", Op: FilterGreater}},
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			name:    "filter unknown operator",
+			sl:      []Query{},
+			args:    args{f: Filter{Key: "b", Value: "true", Op: "~"}},
+			wantRes: nil,
+			wantErr: true,
+		},
+		{
+			name: "filter nested field",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"country": "BY"}}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"country": "RU"}}}},
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"address": "BY"}}},
+				{Key: "3", Object: SimpleQueue{Context: map[string]interface{}{"country": "BY"}}},
+			},
+			args: args{f: Filter{Key: "address.country", Value: "BY"}},
+			wantRes: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"country": "BY"}}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter array index",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"qty": 2.0}}}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"qty": 5.0}}}}},
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{}}}},
+			},
+			args: args{f: Filter{Key: "items.0.qty", Value: "3", Op: FilterGreater}},
+			wantRes: []Query{
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"qty": 5.0}}}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "filter top-level key with dot",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"a.b": "x"}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"a": map[string]interface{}{"b": "y"}}}},
+			},
+			args: args{f: Filter{Key: "a.b", Value: "x"}},
+			wantRes: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"a.b": "x"}}},
+			},
+			wantErr: false,
+		},
 		{
 			name:    "filter empty",
 			sl:      []Query{},
@@ -307,6 +438,36 @@ func TestSimpleQuery_Sort(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "sort nested field",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"zip": 3.0}}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"address": "none"}}},
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"zip": 1.0}}}},
+			},
+			args: args{s: Sort{Field: "address.zip", Asc: true}},
+			want: []Query{
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"zip": 1.0}}}},
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"address": map[string]interface{}{"zip": 3.0}}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"address": "none"}}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "sort array index desc",
+			sl: []Query{
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": "a"}}}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{}}}},
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": "b"}}}}},
+			},
+			args: args{s: Sort{Field: "items.0.sku", Asc: false}},
+			want: []Query{
+				{Key: "2", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": "b"}}}}},
+				{Key: "0", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{map[string]interface{}{"sku": "a"}}}}},
+				{Key: "1", Object: SimpleQueue{Context: map[string]interface{}{"items": []interface{}{}}}},
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,189 @@
+package leveldb
+
+import (
+	"fmt"
+)
+
+// orderedList open queue which order can be changed by client. Priority mode keeps order by itself
+func orderedList(ctx TransactionContextInterface, queue string) (*list, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessEdit); err != nil {
+		return nil, err
+	}
+
+	if l.meta.Config.Mode == QueueModePriority {
+		return nil, fmt.Errorf("queue %q in priority mode: use ChangePriority", queue)
+	}
+
+	return l, nil
+}
+
+// move unlink element and link it right after prev. Empty prev means the head.
+// Key is the only source of order, so element gets key placed between new neighbours while created_at and
+// other element state stay untouched. Key is kept when it's already placed between them.
+// Scheduled element is parked again under new key. Leased element can't be moved: consumer wouldn't find it by key to Ack or Nack
+func move(ctx TransactionContextInterface, l *list, key string, prev func() (string, error)) (*Query, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkNotLeased(l, key, now); err != nil {
+		return nil, err
+	}
+
+	e, err := l.remove(key)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := prev()
+	if err != nil {
+		return nil, err
+	}
+
+	before := l.meta.Head
+	if after != "" {
+		p, err := l.mustGet(after)
+		if err != nil {
+			return nil, err
+		}
+
+		before = p.Next
+	}
+
+	to := key
+
+	if (after != "" && key <= after) || (before != "" && key >= before) {
+		switch {
+		case after == "":
+			to, err = newFrontKey(ctx, before, now)
+		case before == "":
+			to, err = newKey(ctx, after, now)
+		default:
+			to = midKey(after, before)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	moved := &entry{SimpleQueue: e.SimpleQueue}
+	if err = l.insertAfter(after, to, moved); err != nil {
+		return nil, err
+	}
+
+	if err = l.hide(to, moved, now); err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return &Query{to, moved.SimpleQueue}, nil
+}
+
+// MoveBefore place element right before target element. Returns element with its new key
+func (s *SimpleQueueContract) MoveBefore(ctx TransactionContextInterface, queue, key, target string) (*Query, error) {
+	l, err := orderedList(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == target {
+		return nil, fmt.Errorf("element %q can't be moved relative to itself", key)
+	}
+
+	t, err := l.get(target)
+	switch {
+	case err != nil:
+		return nil, err
+	case t == nil:
+		return nil, fmt.Errorf("target element %q not exists", target)
+	case t.Parked != "":
+		return nil, fmt.Errorf("target element %q is scheduled or leased, it's out of queue order", target)
+	}
+
+	// cached target is relinked when element is removed
+	return move(ctx, l, key, func() (string, error) {
+		return t.Prev, nil
+	})
+}
+
+// MoveAfter place element right after target element. Returns element with its new key
+func (s *SimpleQueueContract) MoveAfter(ctx TransactionContextInterface, queue, key, target string) (*Query, error) {
+	l, err := orderedList(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if key == target {
+		return nil, fmt.Errorf("element %q can't be moved relative to itself", key)
+	}
+
+	t, err := l.get(target)
+	switch {
+	case err != nil:
+		return nil, err
+	case t == nil:
+		return nil, fmt.Errorf("target element %q not exists", target)
+	case t.Parked != "":
+		return nil, fmt.Errorf("target element %q is scheduled or leased, it's out of queue order", target)
+	}
+
+	return move(ctx, l, key, func() (string, error) {
+		return target, nil
+	})
+}
+
+// MoveTo place element at zero based position counted from the head. Returns element with its new key.
+// Position is reached by walking links from the nearest edge of queue
+func (s *SimpleQueueContract) MoveTo(ctx TransactionContextInterface, queue, key string, index int) (*Query, error) {
+	l, err := orderedList(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if index < 0 || index >= l.meta.linked() {
+		return nil, fmt.Errorf("index %d out of range [0, %d)", index, l.meta.linked())
+	}
+
+	return move(ctx, l, key, func() (string, error) {
+		// element is already unlinked, so new neighbours are elements at index-1 and index
+		if index == 0 {
+			return "", nil
+		}
+
+		if index <= l.meta.linked()/2 {
+			prev := l.meta.Head
+			for i := 1; i < index; i++ {
+				e, err := l.mustGet(prev)
+				if err != nil {
+					return "", err
+				}
+
+				prev = e.Next
+			}
+
+			return prev, nil
+		}
+
+		prev := l.meta.Tail
+		for i := l.meta.linked(); i > index; i-- {
+			e, err := l.mustGet(prev)
+			if err != nil {
+				return "", err
+			}
+
+			prev = e.Prev
+		}
+
+		return prev, nil
+	})
+}
@@ -0,0 +1,183 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+	"time"
+)
+
+func (s *Suite) TestMove() {
+	const queue = "move"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	var items []*Query
+	for i := 0; i < 4; i++ {
+		q, err := s.contract.PushBack(s.clientCtx("alice", now.Add(time.Duration(i)*time.Second)), queue, `{}`)
+		s.NoError(err)
+
+		items = append(items, q)
+	}
+
+	a, b, c, d := items[0], items[1], items[2], items[3]
+
+	order := func(want ...*Query) {
+		res, err := s.contract.GetAll(ctx, queue)
+		s.NoError(err)
+
+		var keys, links []string
+		for _, q := range want {
+			keys = append(keys, q.Key)
+		}
+
+		for _, q := range res {
+			links = append(links, q.Key)
+		}
+
+		s.Equal(keys, links)
+
+		// links follow key order, parked elements are out of links
+		l, err := openList(ctx.GetStub(), queue)
+		s.NoError(err)
+
+		links = links[:0]
+		for key := l.meta.Head; key != ""; {
+			links = append(links, key)
+
+			e, err := l.mustGet(key)
+			s.NoError(err)
+
+			key = e.Next
+		}
+
+		var linked []string
+		for _, key := range keys {
+			e, err := l.mustGet(key)
+			s.NoError(err)
+
+			if e.Parked == "" {
+				linked = append(linked, key)
+			}
+		}
+
+		s.Equal(linked, links)
+	}
+
+	// between neighbours: created_at stays, key is placed between them
+	moved, err := s.contract.MoveBefore(s.clientCtx("alice", now.Add(time.Hour)), queue, d.Key, b.Key)
+	s.NoError(err)
+	s.True(d.Object.Time.Equal(moved.Object.Time))
+	s.NotEqual(d.Key, moved.Key)
+	d = moved
+	order(a, d, b, c)
+
+	// the head gets front key
+	moved, err = s.contract.MoveAfter(s.clientCtx("alice", now.Add(time.Hour)), queue, c.Key, d.Key)
+	s.NoError(err)
+	c = moved
+	order(a, d, c, b)
+
+	moved, err = s.contract.MoveTo(s.clientCtx("alice", now.Add(time.Hour)), queue, b.Key, 0)
+	s.NoError(err)
+	s.Less(moved.Key, a.Key)
+	s.True(b.Object.Time.Equal(moved.Object.Time))
+	b = moved
+	order(b, a, d, c)
+
+	// the tail is walked from the back
+	moved, err = s.contract.MoveTo(s.clientCtx("alice", now.Add(time.Hour)), queue, b.Key, 3)
+	s.NoError(err)
+	s.Greater(moved.Key, c.Key)
+	b = moved
+	order(a, d, c, b)
+
+	// same position keeps the key
+	moved, err = s.contract.MoveAfter(s.clientCtx("alice", now.Add(time.Hour)), queue, c.Key, d.Key)
+	s.NoError(err)
+	s.Equal(c.Key, moved.Key)
+	order(a, d, c, b)
+
+	// repeated moves into the same gap
+	for i := 0; i < 10; i++ {
+		moved, err = s.contract.MoveBefore(s.clientCtx("alice", now), queue, d.Key, c.Key)
+		s.NoError(err)
+		d = moved
+
+		moved, err = s.contract.MoveBefore(s.clientCtx("alice", now), queue, c.Key, d.Key)
+		s.NoError(err)
+		c = moved
+	}
+	order(a, c, d, b)
+
+	stats, err := s.contract.Stats(ctx, queue)
+	s.NoError(err)
+	s.Equal(4, stats.Length)
+	s.Equal(s.storedBytes(queue), stats.Bytes)
+
+	s.Run("errors", func() {
+		_, err := s.contract.MoveTo(ctx, queue, a.Key, 4)
+		s.Error(err)
+
+		_, err = s.contract.MoveTo(ctx, queue, a.Key, -1)
+		s.Error(err)
+
+		_, err = s.contract.MoveBefore(ctx, queue, a.Key, a.Key)
+		s.Error(err)
+
+		_, err = s.contract.MoveAfter(s.clientCtx("alice", now), queue, "not-exists", a.Key)
+		s.Error(err)
+
+		_, err = s.contract.MoveAfter(s.clientCtx("alice", now), queue, a.Key, "not-exists")
+		s.Error(err)
+	})
+
+	s.Run("leased", func() {
+		alice := s.clientCtx("alice", now)
+
+		res, err := s.contract.Receive(alice, queue, 1, "1m")
+		s.NoError(err)
+		s.Equal(a.Key, res[0].Key)
+
+		_, err = s.contract.MoveAfter(s.clientCtx("bob", now), queue, a.Key, b.Key)
+		s.True(errors.Is(err, ErrLeased))
+
+		// leased target is out of links
+		_, err = s.contract.MoveBefore(s.clientCtx("bob", now), queue, b.Key, a.Key)
+		s.Error(err)
+		order(a, c, d, b)
+
+		s.NoError(s.contract.Ack(alice, queue, a.Key, res[0].Object.Lease.ID))
+		order(c, d, b)
+
+		// expired lease doesn't hold element
+		res, err = s.contract.Receive(alice, queue, 1, "1m")
+		s.NoError(err)
+
+		moved, err := s.contract.MoveTo(s.clientCtx("bob", now.Add(time.Minute)), queue, res[0].Key, 2)
+		s.NoError(err)
+		c = moved
+		order(d, b, c)
+	})
+
+	s.Run("priority", func() {
+		const queue = "move-priority"
+
+		_, err := s.contract.CreateQueue(ctx, queue)
+		s.NoError(err)
+
+		_, err = s.contract.ConfigureQueue(ctx, queue, `{"mode":"priority"}`)
+		s.NoError(err)
+
+		_, err = s.contract.MoveTo(ctx, queue, a.Key, 0)
+		s.Error(err)
+
+		s.NoError(s.contract.DeleteQueue(ctx, queue))
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
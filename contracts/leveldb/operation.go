@@ -9,6 +9,57 @@ import (
 type Filter struct {
 	Key   string
 	Value string
+
+	// Op comparison operator of context field with Value, equality when empty
+	Op string
+}
+
+// Filter comparison operators. Numbers compared as numbers, RFC3339 times as times, other strings lexicographically
+const (
+	FilterEqual          = "="
+	FilterNotEqual       = "!="
+	FilterGreater        = ">"
+	FilterGreaterOrEqual = ">="
+	FilterLess           = "<"
+	FilterLessOrEqual    = "<="
+)
+
+// Filter keyword operators separated from field by spaces. IN and NOT IN take comma separated list of values:
+// country IN BY,RU. EXISTS and MISSING check presence of the field and take no value
+const (
+	FilterIn      = "IN"
+	FilterNotIn   = "NOT IN"
+	FilterExists  = "EXISTS"
+	FilterMissing = "MISSING"
+)
+
+// FilterNull value of filter which matches JSON null: deleted_at=null
+const FilterNull = "null"
+
+// filterOperators ordered so two character operators are matched first
+var filterOperators = []string{FilterNotEqual, FilterGreaterOrEqual, FilterLessOrEqual, FilterEqual, FilterGreater, FilterLess}
+
+var filterKeywords = []string{FilterIn, FilterNotIn, FilterExists, FilterMissing}
+
+// parseFilter split filter expression by the first comparison operator: amount>=1000
+func parseFilter(expr string) (Filter, error) {
+	i := strings.IndexAny(expr, "!=<>")
+	if i <= 0 {
+		return Filter{}, fmt.Errorf(`wrong filter format %q. expected context field, operator and value. example: "country=RU", "amount>1000", "country IN BY,RU", "email EXISTS"`, expr)
+	}
+
+	for _, op := range filterOperators {
+		if strings.HasPrefix(expr[i:], op) {
+			f := Filter{Key: expr[:i], Value: expr[i+len(op):]}
+			if op != FilterEqual {
+				f.Op = op
+			}
+
+			return f, nil
+		}
+	}
+
+	return Filter{}, fmt.Errorf("unknown operator in filter %q. supported: = != > >= < <=", expr)
 }
 
 type Selector struct {
@@ -23,7 +74,7 @@ type Sort struct {
 
 type operation struct {
 	Selector Selector
-	Filter   Filter
+	Filter   Condition
 	Sort     Sort
 }
 
@@ -36,7 +87,9 @@ const (
 
 // ParseOperation as url query
 // Selector: from, to
-// Filter: Filter
+// Filter: Filter, comparison with one of operators: = != > >= < <=, IN and NOT IN list, EXISTS or MISSING check.
+// Comparisons can be combined with AND, OR, NOT and parentheses, values with spaces or parentheses are quoted.
+// Several filter arguments are combined with AND
 // Sort: Sort, argument prefix support - DESC
 func ParseOperation(op string) (*operation, error) {
 	q, err := url.ParseQuery(op)
@@ -57,13 +110,18 @@ func ParseOperation(op string) (*operation, error) {
 		case QuerySelectorTo:
 			res.Selector.To = vals[0]
 		case QueryFilter:
-			f := strings.Split(vals[0], "=")
-			if len(f) != 2 {
-				return nil, fmt.Errorf(`wrong Filter format. support only context field equasion. example: "country=RU"`)
-			}
+			for _, v := range vals {
+				if v == "" {
+					continue
+				}
 
-			res.Filter.Key = f[0]
-			res.Filter.Value = f[1]
+				c, err := parseCondition(v)
+				if err != nil {
+					return nil, err
+				}
+
+				res.Filter = res.Filter.and(c)
+			}
 		case QuerySort:
 			if vals[0][0] != '-'{
 				res.Sort.Asc = true
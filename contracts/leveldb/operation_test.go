@@ -25,9 +25,11 @@ func TestParseOperation(t *testing.T) {
 					From: "0",
 					To:   "1558080533-758077000",
 				},
-				Filter: Filter{
-					Key:   "country",
-					Value: "BY",
+				Filter: Condition{
+					Filter: Filter{
+						Key:   "country",
+						Value: "BY",
+					},
 				},
 				Sort: Sort{
 					Field: "country",
@@ -44,9 +46,11 @@ func TestParseOperation(t *testing.T) {
 					From: "0",
 					To:   "1558080533-758077000",
 				},
-				Filter: Filter{
-					Key:   "country",
-					Value: "BY",
+				Filter: Condition{
+					Filter: Filter{
+						Key:   "country",
+						Value: "BY",
+					},
 				},
 				Sort: Sort{
 					Field: "country",
@@ -59,9 +63,11 @@ func TestParseOperation(t *testing.T) {
 			"filter-only",
 			args{op: "filter=country=BY"},
 			&operation{
-				Filter: Filter{
-					Key:   "country",
-					Value: "BY",
+				Filter: Condition{
+					Filter: Filter{
+						Key:   "country",
+						Value: "BY",
+					},
 				},
 			},
 			false,
@@ -72,6 +78,167 @@ func TestParseOperation(t *testing.T) {
 			nil,
 			true,
 		},
+		{
+			"filter-greater-or-equal",
+			args{op: "filter=amount>=1000"},
+			&operation{
+				Filter: Condition{
+					Filter: Filter{
+						Key:   "amount",
+						Value: "1000",
+						Op:    FilterGreaterOrEqual,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-not-equal",
+			args{op: "filter=country!=BY"},
+			&operation{
+				Filter: Condition{
+					Filter: Filter{
+						Key:   "country",
+						Value: "BY",
+						Op:    FilterNotEqual,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-less-time",
+			args{op: "filter=at<2021-05-17T11:08:53Z"},
+			&operation{
+				Filter: Condition{
+					Filter: Filter{
+						Key:   "at",
+						Value: "2021-05-17T11:08:53Z",
+						Op:    FilterLess,
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-without-field",
+			args{op: "filter=>1000"},
+			nil,
+			true,
+		},
+		{
+			"filter-unknown-operator",
+			args{op: "filter=amount!1000"},
+			nil,
+			true,
+		},
+		{
+			"filter-several",
+			args{op: "filter=country=BY&filter=amount>1000"},
+			&operation{
+				Filter: Condition{
+					Op: ConditionAnd,
+					Args: []Condition{
+						{Filter: Filter{Key: "country", Value: "BY"}},
+						{Filter: Filter{Key: "amount", Value: "1000", Op: FilterGreater}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-expression",
+			args{op: "filter=country=BY+or+(amount>1000+AND+NOT+status=done)"},
+			&operation{
+				Filter: Condition{
+					Op: ConditionOr,
+					Args: []Condition{
+						{Filter: Filter{Key: "country", Value: "BY"}},
+						{
+							Op: ConditionAnd,
+							Args: []Condition{
+								{Filter: Filter{Key: "amount", Value: "1000", Op: FilterGreater}},
+								{Op: ConditionNot, Args: []Condition{{Filter: Filter{Key: "status", Value: "done"}}}},
+							},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-membership",
+			args{op: "filter=country+not+in+BY,RU+AND+email+EXISTS+AND+deleted_at=null"},
+			&operation{
+				Filter: Condition{
+					Op: ConditionAnd,
+					Args: []Condition{
+						{Filter: Filter{Key: "country", Value: "BY,RU", Op: FilterNotIn}},
+						{Filter: Filter{Key: "email", Op: FilterExists}},
+						{Filter: Filter{Key: "deleted_at", Value: FilterNull}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-unclosed-group",
+			args{op: "filter=(country=BY OR country=RU"},
+			nil,
+			true,
+		},
+		{
+			"filter-dangling-operator",
+			args{op: "filter=country=BY AND"},
+			nil,
+			true,
+		},
+		{
+			"filter-missing-operator",
+			args{op: "filter=(country=BY) country=RU"},
+			nil,
+			true,
+		},
+		{
+			"filter-value-with-spaces",
+			args{op: "filter=city=New+York"},
+			&operation{
+				Filter: Condition{
+					Filter: Filter{Key: "city", Value: "New York"},
+				},
+			},
+			false,
+		},
+		{
+			"filter-value-with-parentheses",
+			args{op: "filter=name=foo(bar)"},
+			&operation{
+				Filter: Condition{
+					Filter: Filter{Key: "name", Value: "foo(bar)"},
+				},
+			},
+			false,
+		},
+		{
+			"filter-quoted",
+			args{op: `filter=city="New York" OR name="foo(bar) AND \"baz\""`},
+			&operation{
+				Filter: Condition{
+					Op: ConditionOr,
+					Args: []Condition{
+						{Filter: Filter{Key: "city", Value: "New York"}},
+						{Filter: Filter{Key: "name", Value: `foo(bar) AND "baz"`}},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"filter-unclosed-quote",
+			args{op: `filter=city="New York`},
+			nil,
+			true,
+		},
 		{
 			"filter empty",
 			args{op: "filter="},
@@ -0,0 +1,137 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// Page part of range extraction. Bookmark is empty when range is over, otherwise it should be passed to the
+// next call as is. Count is amount of records fetched from the ledger for this page, it can be greater than
+// amount of results because expired, scheduled and filtered elements are excluded
+type Page struct {
+	Results  []Query `json:"results"`
+	Bookmark string  `json:"bookmark"`
+	Count    int     `json:"count"`
+}
+
+// page read up to pageSize elements of range [from, to) starting from bookmark.
+// Peer uses bookmark as start key of range, so the first page starts from the key of @from element
+func (l *list) page(from, to string, pageSize int, bookmark string) (*Page, error) {
+	if pageSize <= 0 || pageSize > math.MaxInt32 {
+		return nil, fmt.Errorf("page size should be positive")
+	}
+
+	if to == "" {
+		to = lastKey
+	}
+
+	// support backport extraction
+	if to < from {
+		from, to = to, from
+	}
+
+	if bookmark == "" && from != "" {
+		k, err := l.key(from)
+		if err != nil {
+			return nil, err
+		}
+
+		bookmark = k
+	}
+
+	itr, meta, err := l.stub.GetStateByPartialCompositeKeyWithPagination(itemObjectType, []string{l.meta.Name}, int32(pageSize), bookmark)
+	switch {
+	case err != nil:
+		return nil, fmt.Errorf("can't get range state: %w", err)
+	case itr == nil:
+		return nil, fmt.Errorf("pagination is not supported by peer")
+	}
+
+	defer itr.Close()
+
+	p := &Page{Results: []Query{}, Bookmark: meta.GetBookmark(), Count: int(meta.GetFetchedRecordsCount())}
+
+	for itr.HasNext() {
+		i, err := itr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("next result error: %w", err)
+		}
+
+		_, attributes, err := l.stub.SplitCompositeKey(i.Key)
+		if err != nil {
+			return nil, fmt.Errorf("split key error: %w", err)
+		}
+
+		key := attributes[1]
+
+		if key >= to {
+			p.Bookmark = ""
+			break
+		}
+
+		if key < from {
+			continue
+		}
+
+		e := &entry{}
+		if err = json.Unmarshal(i.Value, e); err != nil {
+			return nil, fmt.Errorf("unmarshal error: %w", err)
+		}
+
+		p.Results = append(p.Results, Query{key, e.SimpleQueue})
+	}
+
+	return p, nil
+}
+
+// GetRangeWithPagination same as GetRange but return one page of up to pageSize elements.
+// Empty @bookmark starts from the beginning of range, bookmark of result continues it. Expired elements are excluded
+func (s *SimpleQueueContract) GetRangeWithPagination(ctx TransactionContextInterface, queue, from, to string, pageSize int, bookmark string) (*Page, error) {
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := l.page(from, to, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Results = append([]Query{}, SimpleQuery(p.Results).Live(now)...)
+
+	return p, nil
+}
+
+// QueryWithPagination same as Query but return one page of up to pageSize fetched elements.
+// Filter is applied to fetched elements, so page can contain less results than pageSize. Sort orders single page only
+func (s *SimpleQueueContract) QueryWithPagination(ctx TransactionContextInterface, queue, operation string, pageSize int, bookmark string) (*Page, error) {
+	op, err := ParseOperation(operation)
+	if err != nil {
+		return nil, fmt.Errorf("read operation parameter error: %w", err)
+	}
+
+	p, err := s.GetRangeWithPagination(ctx, queue, op.Selector.From, op.Selector.To, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("extract range error: %w", err)
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := op.apply(p.Results, now)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Results = append([]Query{}, res...)
+
+	return p, nil
+}
@@ -0,0 +1,151 @@
+// +build unit
+
+package leveldb
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	pb "github.com/hyperledger/fabric-protos-go/peer"
+)
+
+// pagingStub implement pagination which MockStub lacks. Bookmark is the key of the first record of next page
+type pagingStub struct {
+	*shimtest.MockStub
+}
+
+func (p *pagingStub) GetStateByPartialCompositeKeyWithPagination(objectType string, keys []string,
+	pageSize int32, bookmark string) (shim.StateQueryIteratorInterface, *pb.QueryResponseMetadata, error) {
+	itr, err := p.GetStateByPartialCompositeKey(objectType, keys)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	defer itr.Close()
+
+	res := &kvIterator{}
+	meta := &pb.QueryResponseMetadata{}
+
+	for itr.HasNext() {
+		kv, err := itr.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if kv.Key < bookmark {
+			continue
+		}
+
+		if len(res.kvs) == int(pageSize) {
+			meta.Bookmark = kv.Key
+			break
+		}
+
+		res.kvs = append(res.kvs, kv)
+	}
+
+	meta.FetchedRecordsCount = int32(len(res.kvs))
+
+	return res, meta, nil
+}
+
+type kvIterator struct {
+	kvs []*queryresult.KV
+}
+
+func (i *kvIterator) HasNext() bool {
+	return len(i.kvs) > 0
+}
+
+func (i *kvIterator) Next() (*queryresult.KV, error) {
+	kv := i.kvs[0]
+	i.kvs = i.kvs[1:]
+
+	return kv, nil
+}
+
+func (i *kvIterator) Close() error {
+	return nil
+}
+
+func (s *Suite) TestPagination() {
+	const queue = "page"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+
+	ctx := &TransactionContext{Clock: FixedClock(now)}
+	ctx.SetStub(&pagingStub{s.stub})
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	items, err := s.contract.PushBackBatch(ctx, queue, `[{"k":"a"},{"k":"b"},{"k":"a"},{"k":"b"},{"k":"a"}]`)
+	s.NoError(err)
+
+	var (
+		res      []Query
+		bookmark string
+		pages    int
+	)
+
+	for {
+		p, err := s.contract.GetRangeWithPagination(ctx, queue, "", "", 2, bookmark)
+		s.NoError(err)
+		s.LessOrEqual(len(p.Results), 2)
+		s.Equal(len(p.Results), p.Count)
+
+		res, bookmark = append(res, p.Results...), p.Bookmark
+		pages++
+
+		if bookmark == "" {
+			break
+		}
+	}
+
+	s.Equal(items, res)
+	s.Equal(3, pages)
+
+	s.Run("range", func() {
+		// the first page starts from the key of @from
+		p, err := s.contract.GetRangeWithPagination(ctx, queue, items[1].Key, items[3].Key, 2, "")
+		s.NoError(err)
+		s.Equal(items[1:3], p.Results)
+		s.Equal(2, p.Count)
+		s.NotEmpty(p.Bookmark)
+
+		p, err = s.contract.GetRangeWithPagination(ctx, queue, items[1].Key, items[3].Key, 2, p.Bookmark)
+		s.NoError(err)
+		s.Empty(p.Results)
+		s.Empty(p.Bookmark)
+
+		p, err = s.contract.GetRangeWithPagination(ctx, queue, items[4].Key, "", 2, "")
+		s.NoError(err)
+		s.Equal(items[4:], p.Results)
+		s.Equal(1, p.Count)
+		s.Empty(p.Bookmark)
+	})
+
+	s.Run("query", func() {
+		p, err := s.contract.QueryWithPagination(ctx, queue, "filter=k=a", 3, "")
+		s.NoError(err)
+		s.Equal([]Query{items[0], items[2]}, p.Results)
+		s.Equal(3, p.Count)
+
+		p, err = s.contract.QueryWithPagination(ctx, queue, "filter=k=c", 3, p.Bookmark)
+		s.NoError(err)
+		s.NotNil(p.Results)
+		s.Empty(p.Results)
+		s.Empty(p.Bookmark)
+	})
+
+	s.Run("errors", func() {
+		_, err := s.contract.GetRangeWithPagination(ctx, queue, "", "", 0, "")
+		s.Error(err)
+
+		// MockStub doesn't support pagination
+		_, err = s.contract.GetRangeWithPagination(s.ctx, queue, "", "", 1, "")
+		s.Error(err)
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
@@ -0,0 +1,169 @@
+package leveldb
+
+import (
+	"fmt"
+	"time"
+)
+
+// parkTime format time attribute of park record. Fixed width keeps records of queue ordered by time
+func parkTime(t time.Time) string {
+	return fmt.Sprintf("%012d-%09d", t.Unix(), t.Nanosecond())
+}
+
+// linked return amount of elements reachable by links
+func (m *QueueMeta) linked() int {
+	return m.Length - m.Parked
+}
+
+// parkKey return ledger key of park record
+func (l *list) parkKey(at, key string) (string, error) {
+	k, err := l.stub.CreateCompositeKey(parkObjectType, []string{l.meta.Name, at, key})
+	if err != nil {
+		return "", fmt.Errorf("create park key of %q error: %w", key, err)
+	}
+
+	return k, nil
+}
+
+// park unlink element which is hidden from consumers till provided time. Walks from the head and the tail don't
+// pass parked elements, they keep their keys and are linked back by settle in key order
+func (l *list) park(key string, e *entry, until time.Time) error {
+	if err := l.unlink(e); err != nil {
+		return err
+	}
+
+	e.Parked = parkTime(until)
+
+	k, err := l.parkKey(e.Parked, key)
+	if err != nil {
+		return err
+	}
+
+	if err = l.stub.PutState(k, []byte(key)); err != nil {
+		return fmt.Errorf("write park record of %q error: %w", key, err)
+	}
+
+	l.meta.Parked++
+
+	return l.put(key, e)
+}
+
+// hide park element which is scheduled after provided time
+func (l *list) hide(key string, e *entry, now time.Time) error {
+	if e.Visible(now) {
+		return nil
+	}
+
+	return l.park(key, e, e.Schedule.NotBefore)
+}
+
+// dropPark delete park record of element, element itself stays out of links
+func (l *list) dropPark(key string, e *entry) error {
+	k, err := l.parkKey(e.Parked, key)
+	if err != nil {
+		return err
+	}
+
+	if l.deferred {
+		l.unparked = append(l.unparked, k)
+	} else if err = l.stub.DelState(k); err != nil {
+		return fmt.Errorf("delete park record of %q error: %w", key, err)
+	}
+
+	e.Parked = ""
+	l.meta.Parked--
+
+	return nil
+}
+
+// unpark link parked element back at the place of its key
+func (l *list) unpark(key string, e *entry) error {
+	if err := l.dropPark(key, e); err != nil {
+		return err
+	}
+
+	prev, err := l.locate(key)
+	if err != nil {
+		return err
+	}
+
+	return l.link(prev, key, e)
+}
+
+// locate return key of element which should precede provided key. Walk goes from the head and from the tail
+// at once, so it's proportional to distance to the nearest edge of queue
+func (l *list) locate(key string) (string, error) {
+	prev, next, back := "", l.meta.Head, l.meta.Tail
+
+	for {
+		if back == "" || back < key {
+			return back, nil
+		}
+
+		if next == "" || next > key {
+			return prev, nil
+		}
+
+		e, err := l.mustGet(back)
+		if err != nil {
+			return "", err
+		}
+
+		back = e.Prev
+
+		if e, err = l.mustGet(next); err != nil {
+			return "", err
+		}
+
+		prev, next = next, e.Next
+	}
+}
+
+// settle link back up to max_batch parked elements which time came. Writes are deferred till save, so
+// operations which don't change the queue see settled elements without writing them
+func (l *list) settle(now time.Time) error {
+	if l.meta.Parked == 0 {
+		return nil
+	}
+
+	itr, err := l.stub.GetStateByPartialCompositeKey(parkObjectType, []string{l.meta.Name})
+	if err != nil {
+		return fmt.Errorf("can't get range state")
+	}
+
+	defer itr.Close()
+
+	l.deferred = true
+	defer func() {
+		l.deferred = false
+	}()
+
+	due := parkTime(now)
+
+	for n := 0; n < l.meta.maxBatch() && itr.HasNext(); n++ {
+		i, err := itr.Next()
+		if err != nil {
+			return fmt.Errorf("next result error: %w", err)
+		}
+
+		_, attributes, err := l.stub.SplitCompositeKey(i.Key)
+		if err != nil {
+			return fmt.Errorf("split key error: %w", err)
+		}
+
+		if attributes[1] > due {
+			break
+		}
+
+		e, err := l.mustGet(attributes[2])
+		if err != nil {
+			return err
+		}
+
+		if err = l.unpark(attributes[2], e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package leveldb
+
+// PeekFront return up to n first visible elements of queue without deleting them. Same as Front scheduled,
+// expired and leased elements are skipped. Walk stops as soon as n elements found
+func (s *SimpleQueueContract) PeekFront(ctx TransactionContextInterface, queue string, n int) ([]Query, error) {
+	return s.peek(ctx, queue, n, true)
+}
+
+// PeekBack return up to n last elements of queue without deleting them. Result starts from the last element.
+// Same as Back scheduled, expired and leased elements are skipped. Walk stops as soon as n elements found
+func (s *SimpleQueueContract) PeekBack(ctx TransactionContextInterface, queue string, n int) ([]Query, error) {
+	return s.peek(ctx, queue, n, false)
+}
+
+// peek walk queue links from the head or from the tail. Queue without suitable elements is not an error
+func (s *SimpleQueueContract) peek(ctx TransactionContextInterface, queue string, n int, fromHead bool) ([]Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkBatch(l, n); err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	key := l.meta.Tail
+	if fromHead {
+		key = l.meta.Head
+	}
+
+	res := make([]Query, 0, n)
+
+	for skipped := 0; key != "" && len(res) < n; {
+		if err = checkWalk(l, skipped); err != nil {
+			if len(res) > 0 {
+				break
+			}
+
+			return nil, err
+		}
+
+		e, err := l.mustGet(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if !e.Expired(now) && !e.Lease.Active(now) && (e.Visible(now) || !fromHead) {
+			res = append(res, Query{key, e.SimpleQueue})
+		} else {
+			skipped++
+		}
+
+		if fromHead {
+			key = e.Next
+		} else {
+			key = e.Prev
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,75 @@
+// +build unit
+
+package leveldb
+
+import (
+	"time"
+)
+
+func (s *Suite) TestPeek() {
+	const queue = "peek"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	res, err := s.contract.PeekFront(ctx, queue, 3)
+	s.NoError(err)
+	s.Empty(res)
+
+	scheduled, err := s.contract.PushBackDelayed(ctx, queue, `{"n":0}`, "1h")
+	s.NoError(err)
+
+	items, err := s.contract.PushBackBatch(ctx, queue, `[{"n":1},{"n":2},{"n":3}]`)
+	s.NoError(err)
+
+	_, err = s.contract.PushBackTTL(ctx, queue, `{"n":4}`, "1s")
+	s.NoError(err)
+
+	later := s.clientCtx("alice", now.Add(time.Minute))
+
+	// scheduled element is parked out of links, expired one is skipped from the tail
+	res, err = s.contract.PeekFront(later, queue, 2)
+	s.NoError(err)
+	s.Equal(items[:2], res)
+
+	res, err = s.contract.PeekBack(later, queue, 2)
+	s.NoError(err)
+	s.Equal([]Query{items[2], items[1]}, res)
+
+	res, err = s.contract.PeekBack(later, queue, 10)
+	s.NoError(err)
+	s.Equal([]Query{items[2], items[1], items[0]}, res)
+
+	all, err := s.contract.GetRange(later, queue, "", "")
+	s.NoError(err)
+	s.Equal(*scheduled, all[0])
+
+	// nothing deleted
+	stats, err := s.contract.Stats(ctx, queue)
+	s.NoError(err)
+	s.Equal(5, stats.Length)
+
+	// leased elements are skipped from both ends
+	leased, err := s.contract.Receive(later, queue, 1, "1m")
+	s.NoError(err)
+	s.Equal(items[0].Key, leased[0].Key)
+
+	res, err = s.contract.PeekFront(later, queue, 2)
+	s.NoError(err)
+	s.Equal(items[1:], res)
+
+	res, err = s.contract.PeekBack(later, queue, 10)
+	s.NoError(err)
+	s.Equal([]Query{items[2], items[1]}, res)
+
+	_, err = s.contract.PeekFront(ctx, queue, 0)
+	s.Error(err)
+
+	_, err = s.contract.PeekBack(ctx, queue, DefaultMaxBatch+1)
+	s.Error(err)
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
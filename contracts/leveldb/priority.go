@@ -0,0 +1,145 @@
+package leveldb
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// QueueModeFIFO elements ordered by creation time
+	QueueModeFIFO = ""
+
+	// QueueModePriority elements ordered by priority and then by creation time
+	QueueModePriority = "priority"
+)
+
+// MaxPriority highest priority of element, the lowest is zero
+const MaxPriority = 9999
+
+// checkPriority validate priority range
+func checkPriority(p int) error {
+	if p < 0 || p > MaxPriority {
+		return fmt.Errorf("priority should be in range [0, %d]", MaxPriority)
+	}
+
+	return nil
+}
+
+// insertByPriority link entry after the last element with the same or higher priority under PriorityKey key.
+// Walk starts from the tail, so insertion of high priority element is proportional to amount of lower ones
+func insertByPriority(ctx TransactionContextInterface, l *list, e *entry) (string, error) {
+	if err := checkPriority(e.Priority); err != nil {
+		return "", err
+	}
+
+	band := priorityBand(e.Priority)
+
+	after := l.meta.Tail
+	// keys of lower priorities are greater than any key of the band
+	for after != "" && after > band+lastKey {
+		prev, err := l.mustGet(after)
+		if err != nil {
+			return "", err
+		}
+
+		after = prev.Prev
+	}
+
+	last := ""
+	if strings.HasPrefix(after, band) {
+		last = strings.TrimPrefix(after, band)
+	}
+
+	key, err := newKey(ctx, last, e.Time)
+	if err != nil {
+		return "", err
+	}
+
+	key = PriorityKey(e.Priority, key)
+
+	if err = l.insertAfter(after, key, e); err != nil {
+		return "", err
+	}
+
+	return key, nil
+}
+
+// priorityList open queue which works in priority mode
+func priorityList(ctx TransactionContextInterface, queue string) (*list, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.meta.Config.Mode != QueueModePriority {
+		return nil, fmt.Errorf("queue %q is not in priority mode", queue)
+	}
+
+	return l, nil
+}
+
+// PushBackPriority create new element with provided priority in priority mode queue.
+// Front returns the oldest element with the highest priority
+func (s *SimpleQueueContract) PushBackPriority(ctx TransactionContextInterface, queue, js string, priority int) (*Query, error) {
+	if _, err := priorityList(ctx, queue); err != nil {
+		return nil, err
+	}
+
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Priority = priority
+
+	return s.pushItem(ctx, queue, item)
+}
+
+// ChangePriority move element of priority mode queue to the end of new priority.
+// Element gets new key, created_at and other element state stay untouched. Leased element can't be changed
+func (s *SimpleQueueContract) ChangePriority(ctx TransactionContextInterface, queue, key string, priority int) (*Query, error) {
+	if err := checkPriority(priority); err != nil {
+		return nil, err
+	}
+
+	l, err := priorityList(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessEdit); err != nil {
+		return nil, err
+	}
+
+	now, err := ctx.Now()
+	if err != nil {
+		return nil, err
+	}
+
+	if err = checkNotLeased(l, key, now); err != nil {
+		return nil, err
+	}
+
+	e, err := l.remove(key)
+	if err != nil {
+		return nil, err
+	}
+
+	moved := &entry{SimpleQueue: e.SimpleQueue}
+	moved.Priority = priority
+
+	to, err := insertByPriority(ctx, l, moved)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.hide(to, moved, now); err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return &Query{to, moved.SimpleQueue}, nil
+}
@@ -0,0 +1,121 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+	"time"
+)
+
+func (s *Suite) TestPriority() {
+	const queue = "priority"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.PushBackPriority(ctx, queue, `{}`, 1)
+	s.Error(err, "fifo queue")
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"mode":"lifo"}`)
+	s.Error(err)
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"mode":"priority"}`)
+	s.NoError(err)
+
+	_, err = s.contract.PushBackPriority(ctx, queue, `{}`, MaxPriority+1)
+	s.Error(err)
+
+	push := func(p int, at time.Duration) *Query {
+		q, err := s.contract.PushBackPriority(s.clientCtx("alice", now.Add(at)), queue, `{}`, p)
+		s.Require().NoError(err)
+		s.Equal(p, q.Object.Priority)
+
+		return q
+	}
+
+	low := push(0, 0)
+	mid := push(5, time.Second)
+	high := push(10, 2*time.Second)
+	mid2 := push(5, 3*time.Second)
+
+	// client clock behind the band tail: key still placed after it
+	midBehind := push(5, 0)
+	t, err := ParseKey(midBehind.Key)
+	s.NoError(err)
+	s.True(now.Add(3*time.Second + time.Nanosecond).Equal(t))
+
+	// plain push uses zero priority
+	plain, err := s.contract.PushBack(ctx, queue, `{}`)
+	s.NoError(err)
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"mode":""}`)
+	s.Error(err, "non empty queue")
+
+	order := func(want ...*Query) {
+		res, err := s.contract.GetAll(ctx, queue)
+		s.NoError(err)
+		s.Require().Len(res, len(want))
+
+		for i, q := range want {
+			s.Equal(q.Key, res[i].Key)
+		}
+
+		front, err := s.contract.Front(ctx, queue)
+		s.NoError(err)
+		s.Equal(want[0].Key, front.Key)
+
+		back, err := s.contract.Back(ctx, queue)
+		s.NoError(err)
+		s.Equal(want[len(want)-1].Key, back.Key)
+	}
+
+	order(high, mid, mid2, midBehind, low, plain)
+
+	s.Run("ChangePriority", func() {
+		_, err := s.contract.ChangePriority(ctx, queue, low.Key, -1)
+		s.Error(err)
+
+		_, err = s.contract.ChangePriority(ctx, queue, "absent", 1)
+		s.Error(err)
+
+		moved, err := s.contract.ChangePriority(ctx, queue, plain.Key, 5)
+		s.NoError(err)
+		s.NotEqual(plain.Key, moved.Key)
+		s.Equal(5, moved.Object.Priority)
+		s.True(plain.Object.Time.Equal(moved.Object.Time))
+
+		top, err := s.contract.ChangePriority(ctx, queue, low.Key, MaxPriority)
+		s.NoError(err)
+
+		order(top, high, mid, mid2, midBehind, moved)
+
+		// lease holder should be able to Ack element by its key
+		leased, err := s.contract.Receive(ctx, queue, 1, "1m")
+		s.NoError(err)
+		s.Equal(top.Key, leased[0].Key)
+
+		_, err = s.contract.ChangePriority(s.clientCtx("bob", now), queue, top.Key, 1)
+		s.True(errors.Is(err, ErrLeased))
+
+		s.NoError(s.contract.Nack(ctx, queue, top.Key, leased[0].Object.Lease.ID))
+
+		res, err := s.contract.PopFrontN(ctx, queue, 10)
+		s.NoError(err)
+		s.Len(res, 6)
+		s.Equal(top.Key, res[0].Key)
+	})
+
+	_, err = s.contract.ConfigureQueue(ctx, queue, `{"mode":""}`)
+	s.NoError(err)
+
+	_, err = s.contract.ChangePriority(ctx, queue, low.Key, 1)
+	s.Error(err, "fifo queue")
+
+	_, err = s.contract.Front(ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
@@ -0,0 +1,228 @@
+package leveldb
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// DefaultQueue name of queue which receive elements of flat queue during MigrateKeys
+const DefaultQueue = "default"
+
+// DeadLetterSuffix appended to queue name to get name of dead-letter queue when it's not configured
+const DeadLetterSuffix = ".dlq"
+
+// QueueConfig behaviour settings of queue. Zero value keeps feature disabled
+type QueueConfig struct {
+	// Mode order of elements: QueueModeFIFO or QueueModePriority. Can be changed only for empty queue.
+	// Contract schema requires at least one property of object, mode is the one which always makes sense
+	Mode string `json:"mode"`
+
+	// MaxReceives amount of deliveries after which element moved to dead-letter queue
+	MaxReceives int `json:"max_receives,omitempty" metadata:"max_receives,optional"`
+
+	// DeadLetterQueue queue which receive elements exceeded MaxReceives. <queue>.dlq when empty
+	DeadLetterQueue string `json:"dead_letter_queue,omitempty" metadata:"dead_letter_queue,optional"`
+
+	// TTL default lifetime of new elements, Go duration
+	TTL string `json:"ttl,omitempty" metadata:"ttl,optional"`
+
+	// DedupWindow how long PushBackIdempotent remember deduplication ID, Go duration. DefaultDedupWindow when empty
+	DedupWindow string `json:"dedup_window,omitempty" metadata:"dedup_window,optional"`
+
+	// MaxBatch limit of elements handled by single batch operation. DefaultMaxBatch when zero
+	MaxBatch int `json:"max_batch,omitempty" metadata:"max_batch,optional"`
+
+	// MaxLength and MaxBytes capacity of queue, zero means unlimited
+	MaxLength int `json:"max_length,omitempty" metadata:"max_length,optional"`
+	MaxBytes  int `json:"max_bytes,omitempty" metadata:"max_bytes,optional"`
+
+	// Overflow policy applied when push exceeds capacity: OverflowReject, OverflowDropOldest or OverflowDropNew
+	Overflow string `json:"overflow,omitempty" metadata:"overflow,optional"`
+
+	// Retention how long elements are kept regardless of consumer groups, Go duration. Unlimited when empty
+	Retention string `json:"retention,omitempty" metadata:"retention,optional"`
+}
+
+// parseDuration parse positive Go duration: 30s, 5m, 24h
+func parseDuration(name, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("parse %s error: %w", name, err)
+	}
+
+	if d <= 0 {
+		return 0, fmt.Errorf("%s should be positive", name)
+	}
+
+	return d, nil
+}
+
+// deadLetterQueue return name of dead-letter queue of provided queue
+func (m *QueueMeta) deadLetterQueue() string {
+	if m.Config.DeadLetterQueue != "" {
+		return m.Config.DeadLetterQueue
+	}
+
+	return m.Name + DeadLetterSuffix
+}
+
+// CreateQueue register new empty named queue
+func (s *SimpleQueueContract) CreateQueue(ctx TransactionContextInterface, name string) (*QueueMeta, error) {
+	l, err := createList(ctx.GetStub(), name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return &l.meta, nil
+}
+
+// ConfigureQueue merge provided JSON into queue configuration. Fields absent in JSON stay untouched
+//
+// example: {"mode":"priority","max_receives":5,"dead_letter_queue":"orders-failed","ttl":"24h","dedup_window":"10m","max_batch":500,"max_length":1000,"overflow":"drop_oldest","retention":"168h"}
+func (s *SimpleQueueContract) ConfigureQueue(ctx TransactionContextInterface, name, js string) (*QueueMeta, error) {
+	l, err := openQueue(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessAdmin); err != nil {
+		return nil, err
+	}
+
+	mode := l.meta.Config.Mode
+
+	if err = json.Unmarshal([]byte(js), &l.meta.Config); err != nil {
+		return nil, fmt.Errorf("unmarshal queue config: %w", err)
+	}
+
+	switch {
+	case l.meta.Config.Mode != QueueModeFIFO && l.meta.Config.Mode != QueueModePriority:
+		return nil, fmt.Errorf("unknown mode %q", l.meta.Config.Mode)
+	case l.meta.Config.Mode != mode && l.meta.Length > 0:
+		return nil, fmt.Errorf("mode of non empty queue can't be changed")
+	case l.meta.Config.MaxReceives < 0:
+		return nil, fmt.Errorf("max_receives should not be negative")
+	case l.meta.Config.MaxBatch < 0:
+		return nil, fmt.Errorf("max_batch should not be negative")
+	case l.meta.Config.MaxLength < 0, l.meta.Config.MaxBytes < 0:
+		return nil, fmt.Errorf("capacity should not be negative")
+	case !validOverflow(l.meta.Config.Overflow):
+		return nil, fmt.Errorf("unknown overflow policy %q", l.meta.Config.Overflow)
+	case l.meta.Config.DeadLetterQueue == name:
+		return nil, fmt.Errorf("queue can't be dead-letter queue of itself")
+	}
+
+	if l.meta.Config.TTL != "" {
+		if _, err = parseDuration("ttl", l.meta.Config.TTL); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.meta.Config.DedupWindow != "" {
+		if _, err = parseDuration("dedup_window", l.meta.Config.DedupWindow); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = l.meta.retention(); err != nil {
+		return nil, err
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return &l.meta, nil
+}
+
+// DeleteQueue remove named queue with all it's elements
+func (s *SimpleQueueContract) DeleteQueue(ctx TransactionContextInterface, name string) error {
+	l, err := openQueue(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	if err = l.allow(accessAdmin); err != nil {
+		return err
+	}
+
+	return l.drop()
+}
+
+// ListQueues return metadata of all named queues ordered by name
+func (s *SimpleQueueContract) ListQueues(ctx TransactionContextInterface) (res []QueueMeta, err error) {
+	itr, err := ctx.GetStub().GetStateByPartialCompositeKey(queueObjectType, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("can't get range state")
+	}
+
+	defer itr.Close()
+
+	for itr.HasNext() {
+		i, err := itr.Next()
+		if err != nil {
+			return nil, fmt.Errorf("next result error: %w", err)
+		}
+
+		meta := QueueMeta{}
+		if err = json.Unmarshal(i.Value, &meta); err != nil {
+			return nil, fmt.Errorf("unmarshal error: %w", err)
+		}
+
+		res = append(res, meta)
+	}
+
+	return res, nil
+}
+
+// QueueStats summary of queue taken from metadata counters and edge elements
+type QueueStats struct {
+	Name   string `json:"name"`
+	Length int    `json:"length"`
+	Bytes  int    `json:"bytes"`
+
+	OldestKey       string    `json:"oldest_key"`
+	OldestCreatedAt time.Time `json:"oldest_created_at"`
+	NewestKey       string    `json:"newest_key"`
+	NewestCreatedAt time.Time `json:"newest_created_at"`
+}
+
+// Stats return length, stored bytes and edge elements of queue without range scan of elements.
+// Length counts parked elements too, but they are not edges. Time values are zero without linked elements
+func (s *SimpleQueueContract) Stats(ctx TransactionContextInterface, name string) (*QueueStats, error) {
+	l, err := openQueue(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &QueueStats{
+		Name:      l.meta.Name,
+		Length:    l.meta.Length,
+		Bytes:     l.meta.Bytes,
+		OldestKey: l.meta.Head,
+		NewestKey: l.meta.Tail,
+	}
+
+	if l.meta.Head == "" {
+		return res, nil
+	}
+
+	head, err := l.mustGet(l.meta.Head)
+	if err != nil {
+		return nil, err
+	}
+
+	tail, err := l.mustGet(l.meta.Tail)
+	if err != nil {
+		return nil, err
+	}
+
+	res.OldestCreatedAt, res.NewestCreatedAt = head.Time, tail.Time
+
+	return res, nil
+}
@@ -0,0 +1,109 @@
+package leveldb
+
+import (
+	"fmt"
+)
+
+// rewrite reorder contexts of elements in range [from, to) in single transaction. Keys, links, created_at and
+// other element state stay in place, same as Swap does. Range is bounded by max_batch of the queue.
+// order receive amount of elements and return for every position index of element which context it gets
+func rewrite(l *list, from, to string, order func(n int) func(i int) int) ([]Query, error) {
+	if to == "" {
+		to = lastKey
+	}
+
+	if to < from {
+		from, to = to, from
+	}
+
+	var (
+		keys    []string
+		entries []*entry
+	)
+
+	err := l.scan(func(key string, e *entry) (bool, error) {
+		if key >= to {
+			return false, nil
+		}
+
+		if key < from {
+			return true, nil
+		}
+
+		if len(keys) == l.meta.maxBatch() {
+			return false, fmt.Errorf("range exceeds batch limit %d", l.meta.maxBatch())
+		}
+
+		keys, entries = append(keys, key), append(entries, e)
+
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	contexts := make([]Context, len(entries))
+	for i, e := range entries {
+		contexts[i] = e.Context
+	}
+
+	src := order(len(entries))
+	res := make([]Query, 0, len(entries))
+
+	for i, e := range entries {
+		if j := src(i); j != i {
+			e.Context = contexts[j]
+
+			if err = l.put(keys[i], e); err != nil {
+				return nil, err
+			}
+		}
+
+		res = append(res, Query{keys[i], e.SimpleQueue})
+	}
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Reverse reverse order of contexts of elements in range [from, to). Empty @to means till the end.
+// Returns elements of range after rewrite
+func (s *SimpleQueueContract) Reverse(ctx TransactionContextInterface, queue, from, to string) ([]Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessEdit); err != nil {
+		return nil, err
+	}
+
+	return rewrite(l, from, to, func(n int) func(int) int {
+		return func(i int) int {
+			return n - 1 - i
+		}
+	})
+}
+
+// Rotate shift contexts of elements in range [from, to) by k positions towards the end, contexts of last k
+// elements move to the beginning of range. Negative k shifts towards the head. Empty @to means till the end.
+// Returns elements of range after rewrite
+func (s *SimpleQueueContract) Rotate(ctx TransactionContextInterface, queue, from, to string, k int) ([]Query, error) {
+	l, err := openQueue(ctx, queue)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = l.allow(accessEdit); err != nil {
+		return nil, err
+	}
+
+	return rewrite(l, from, to, func(n int) func(int) int {
+		return func(i int) int {
+			return ((i-k)%n + n) % n
+		}
+	})
+}
@@ -0,0 +1,76 @@
+// +build unit
+
+package leveldb
+
+func (s *Suite) TestRewrite() {
+	const queue = "rewrite"
+
+	_, err := s.contract.CreateQueue(s.ctx, queue)
+	s.NoError(err)
+
+	items, err := s.contract.PushBackBatch(s.ctx, queue, `[{"n":0},{"n":1},{"n":2},{"n":3},{"n":4}]`)
+	s.NoError(err)
+
+	contexts := func() (res []interface{}) {
+		all, err := s.contract.GetAll(s.ctx, queue)
+		s.NoError(err)
+
+		for i, q := range all {
+			// keys and created_at stay in place
+			s.Equal(items[i].Key, q.Key)
+			s.True(items[i].Object.Time.Equal(q.Object.Time))
+
+			res = append(res, q.Object.Context["n"])
+		}
+
+		return res
+	}
+
+	res, err := s.contract.Reverse(s.ctx, queue, items[1].Key, items[4].Key)
+	s.NoError(err)
+	s.Len(res, 3)
+	s.Equal(items[1].Key, res[0].Key)
+	s.Equal([]interface{}{0.0, 3.0, 2.0, 1.0, 4.0}, contexts())
+
+	res, err = s.contract.Reverse(s.ctx, queue, "", "")
+	s.NoError(err)
+	s.Len(res, 5)
+	s.Equal([]interface{}{4.0, 1.0, 2.0, 3.0, 0.0}, contexts())
+
+	_, err = s.contract.Rotate(s.ctx, queue, "", "", 2)
+	s.NoError(err)
+	s.Equal([]interface{}{3.0, 0.0, 4.0, 1.0, 2.0}, contexts())
+
+	_, err = s.contract.Rotate(s.ctx, queue, items[0].Key, items[3].Key, -1)
+	s.NoError(err)
+	s.Equal([]interface{}{0.0, 4.0, 3.0, 1.0, 2.0}, contexts())
+
+	// full turn changes nothing
+	_, err = s.contract.Rotate(s.ctx, queue, "", "", 10)
+	s.NoError(err)
+	s.Equal([]interface{}{0.0, 4.0, 3.0, 1.0, 2.0}, contexts())
+
+	stats, err := s.contract.Stats(s.ctx, queue)
+	s.NoError(err)
+	s.Equal(s.storedBytes(queue), stats.Bytes)
+
+	s.Run("batch limit", func() {
+		_, err := s.contract.ConfigureQueue(s.ctx, queue, `{"max_batch":4}`)
+		s.NoError(err)
+
+		_, err = s.contract.Reverse(s.ctx, queue, "", "")
+		s.Error(err)
+
+		_, err = s.contract.Rotate(s.ctx, queue, items[1].Key, "", 1)
+		s.NoError(err)
+		s.Equal([]interface{}{0.0, 2.0, 4.0, 3.0, 1.0}, contexts())
+	})
+
+	s.Run("empty range", func() {
+		res, err := s.contract.Rotate(s.ctx, queue, items[2].Key, items[2].Key, 1)
+		s.NoError(err)
+		s.Empty(res)
+	})
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+}
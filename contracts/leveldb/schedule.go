@@ -0,0 +1,61 @@
+package leveldb
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrWalkLimit returned when search of available element passed max_batch elements which can't be taken.
+// Expired elements stay linked till PurgeExpired deletes them, so they can pile up at the head of the queue
+var ErrWalkLimit = errors.New("walk limit exceeded")
+
+// checkWalk bound walk over elements which can't be taken by max_batch of the queue
+func checkWalk(l *list, skipped int) error {
+	if skipped < l.meta.maxBatch() {
+		return nil
+	}
+
+	return fmt.Errorf("passed %d elements which can't be taken, walk is limited by max_batch: %w", skipped, ErrWalkLimit)
+}
+
+// PushBackAt create new queue element which is hidden from Front, Back, pops, Query and Receive till notBefore.
+// Element is parked out of queue links, so consumers don't walk over it, and is linked back by its key in time
+// @notBefore - RFC3339 time
+func (s *SimpleQueueContract) PushBackAt(ctx TransactionContextInterface, queue, js, notBefore string) (*Query, error) {
+	t, err := time.Parse(time.RFC3339Nano, notBefore)
+	if err != nil {
+		return nil, fmt.Errorf("parse not before time error: %w", err)
+	}
+
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Schedule = &Schedule{NotBefore: t.UTC()}
+
+	return s.pushItem(ctx, queue, item)
+}
+
+// PushBackDelayed create new queue element which is hidden for provided duration since transaction time.
+// @delay - Go duration: 30s, 5m, 1h
+func (s *SimpleQueueContract) PushBackDelayed(ctx TransactionContextInterface, queue, js, delay string) (*Query, error) {
+	d, err := time.ParseDuration(delay)
+	if err != nil {
+		return nil, fmt.Errorf("parse delay error: %w", err)
+	}
+
+	if d < 0 {
+		return nil, fmt.Errorf("delay should not be negative")
+	}
+
+	item, err := newItem(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Schedule = &Schedule{NotBefore: item.Time.Add(d)}
+
+	return s.pushItem(ctx, queue, item)
+}
@@ -0,0 +1,145 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+	"time"
+)
+
+func (s *Suite) TestSchedule() {
+	const queue = "schedule"
+
+	now := mustParse("2021-05-17T11:08:53+03:00")
+	ctx := s.clientCtx("alice", now)
+
+	_, err := s.contract.CreateQueue(ctx, queue)
+	s.NoError(err)
+
+	_, err = s.contract.PushBackAt(ctx, queue, `{}`, "tomorrow")
+	s.Error(err)
+
+	_, err = s.contract.PushBackDelayed(ctx, queue, `{}`, "-1s")
+	s.Error(err)
+
+	at, err := s.contract.PushBackAt(ctx, queue, `{"n":1}`, "2021-05-17T12:08:53+03:00")
+	s.NoError(err)
+	s.True(now.Add(time.Hour).Equal(at.Object.Schedule.NotBefore))
+
+	delayed, err := s.contract.PushBackDelayed(ctx, queue, `{"n":2}`, "30m")
+	s.NoError(err)
+	s.True(now.Add(30 * time.Minute).Equal(delayed.Object.Schedule.NotBefore))
+
+	_, err = s.contract.Front(ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	_, err = s.contract.PopFront(ctx, queue)
+	s.True(errors.Is(err, ErrEmptyQueue))
+
+	plain, err := s.contract.PushBack(ctx, queue, `{"n":3}`)
+	s.NoError(err)
+	s.Nil(plain.Object.Schedule)
+
+	front, err := s.contract.Front(ctx, queue)
+	s.NoError(err)
+	s.Equal(plain.Key, front.Key)
+
+	res, err := s.contract.Query(ctx, queue, "")
+	s.NoError(err)
+	s.Equal([]Query{*plain}, res)
+
+	leased, err := s.contract.Receive(ctx, queue, 10, "1m")
+	s.NoError(err)
+	s.Len(leased, 1)
+	s.Equal(plain.Key, leased[0].Key)
+
+	// range still shows scheduled elements
+	all, err := s.contract.GetRange(ctx, queue, "", "")
+	s.NoError(err)
+	s.Len(all, 3)
+	s.Equal(at.Object.Schedule, all[0].Object.Schedule)
+	s.Equal(delayed.Object.Schedule, all[1].Object.Schedule)
+
+	s.Run("came", func() {
+		later := s.clientCtx("alice", now.Add(30*time.Minute))
+
+		front, err := s.contract.Front(later, queue)
+		s.NoError(err)
+		s.Equal(delayed.Key, front.Key)
+
+		res, err := s.contract.Query(later, queue, "")
+		s.NoError(err)
+		s.Len(res, 2)
+
+		later = s.clientCtx("alice", now.Add(time.Hour))
+
+		first, err := s.contract.PopFront(later, queue)
+		s.NoError(err)
+		s.Equal(at.Key, first.Key)
+
+		second, err := s.contract.PopFront(later, queue)
+		s.NoError(err)
+		s.Equal(delayed.Key, second.Key)
+	})
+
+	s.Run("parked", func() {
+		const queue = "schedule-parked"
+
+		_, err := s.contract.CreateQueue(ctx, queue)
+		s.NoError(err)
+
+		_, err = s.contract.ConfigureQueue(ctx, queue, `{"max_batch":2}`)
+		s.NoError(err)
+
+		var delayed []Query
+
+		for i := 0; i < 3; i++ {
+			q, err := s.contract.PushBackDelayed(ctx, queue, `{}`, "720h")
+			s.NoError(err)
+
+			delayed = append(delayed, *q)
+		}
+
+		plain, err := s.contract.PushBack(ctx, queue, `{}`)
+		s.NoError(err)
+
+		// scheduled elements are out of links, consumers don't walk over them
+		front, err := s.contract.Front(ctx, queue)
+		s.NoError(err)
+		s.Equal(plain.Key, front.Key)
+
+		res, err := s.contract.PeekFront(ctx, queue, 2)
+		s.NoError(err)
+		s.Equal([]Query{*plain}, res)
+
+		back, err := s.contract.Back(ctx, queue)
+		s.NoError(err)
+		s.Equal(plain.Key, back.Key)
+
+		res, err = s.contract.PopFrontN(ctx, queue, 2)
+		s.NoError(err)
+		s.Equal([]Query{*plain}, res)
+
+		_, err = s.contract.Front(ctx, queue)
+		s.True(errors.Is(err, ErrEmptyQueue))
+
+		stats, err := s.contract.Stats(ctx, queue)
+		s.NoError(err)
+		s.Equal(3, stats.Length)
+
+		// elements are linked back in key order, up to max_batch per transaction
+		later := s.clientCtx("alice", now.Add(720*time.Hour))
+
+		res, err = s.contract.PopFrontN(later, queue, 2)
+		s.NoError(err)
+		s.Equal(delayed[:2], res)
+
+		front, err = s.contract.Front(later, queue)
+		s.NoError(err)
+		s.Equal(delayed[2], *front)
+
+		s.NoError(s.contract.DeleteQueue(ctx, queue))
+	})
+
+	s.NoError(s.contract.DeleteQueue(ctx, queue))
+}
@@ -0,0 +1,88 @@
+package leveldb
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// QueueStateActive queue accepts every operation
+	QueueStateActive = ""
+
+	// QueueStatePaused queue rejects pushes, consumers and administrative edits keep working
+	QueueStatePaused = "paused"
+
+	// QueueStateDraining queue rejects pushes and edits of elements, elements can only be consumed or removed
+	QueueStateDraining = "draining"
+
+	// QueueStateFrozen queue rejects every mutation, elements can only be read
+	QueueStateFrozen = "frozen"
+)
+
+// ErrQueueState returned when operation is not allowed in current state of queue
+var ErrQueueState = errors.New("operation is not allowed in queue state")
+
+// access kind of mutation performed by transaction
+type access string
+
+const (
+	// accessPush add elements to the queue
+	accessPush access = "push"
+
+	// accessEdit change elements or their order
+	accessEdit access = "edit"
+
+	// accessPop consume or remove elements
+	accessPop access = "pop"
+
+	// accessAdmin change configuration of queue or delete it
+	accessAdmin access = "admin"
+)
+
+// allow check that mutation is permitted in current state of queue
+func (l *list) allow(op access) error {
+	switch state := l.meta.State; {
+	case state == QueueStateFrozen,
+		state == QueueStateDraining && op != accessPop && op != accessAdmin,
+		state == QueueStatePaused && op == accessPush:
+		return fmt.Errorf("queue %q is %s, %s rejected: %w", l.meta.Name, state, op, ErrQueueState)
+	}
+
+	return nil
+}
+
+// setState switch queue into provided state. Switch is allowed from any state
+func setState(ctx TransactionContextInterface, name, state string) (*QueueMeta, error) {
+	l, err := openQueue(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	l.meta.State = state
+
+	if err = l.save(); err != nil {
+		return nil, err
+	}
+
+	return &l.meta, nil
+}
+
+// Pause stop producers: queue rejects pushes till Resume
+func (s *SimpleQueueContract) Pause(ctx TransactionContextInterface, name string) (*QueueMeta, error) {
+	return setState(ctx, name, QueueStatePaused)
+}
+
+// Drain stop producers and edits: elements can only be consumed or removed till Resume
+func (s *SimpleQueueContract) Drain(ctx TransactionContextInterface, name string) (*QueueMeta, error) {
+	return setState(ctx, name, QueueStateDraining)
+}
+
+// Freeze make queue read only till Resume
+func (s *SimpleQueueContract) Freeze(ctx TransactionContextInterface, name string) (*QueueMeta, error) {
+	return setState(ctx, name, QueueStateFrozen)
+}
+
+// Resume return queue into active state
+func (s *SimpleQueueContract) Resume(ctx TransactionContextInterface, name string) (*QueueMeta, error) {
+	return setState(ctx, name, QueueStateActive)
+}
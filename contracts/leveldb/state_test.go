@@ -0,0 +1,150 @@
+// +build unit
+
+package leveldb
+
+import (
+	"errors"
+)
+
+func (s *Suite) TestQueueState() {
+	const queue = "state"
+
+	_, err := s.contract.CreateQueue(s.ctx, queue)
+	s.NoError(err)
+
+	items, err := s.contract.PushBackBatch(s.ctx, queue, `[{"n":0},{"n":1},{"n":2},{"n":3}]`)
+	s.NoError(err)
+
+	rejected := func(err error) {
+		s.True(errors.Is(err, ErrQueueState), "got %v", err)
+	}
+
+	push := func() error {
+		_, err := s.contract.PushBack(s.ctx, queue, `{}`)
+		return err
+	}
+
+	edit := func() error {
+		_, err := s.contract.Update(s.ctx, queue, items[3].Key, `{"n":30}`)
+		return err
+	}
+
+	reads := func() {
+		_, err := s.contract.Get(s.ctx, queue, items[3].Key)
+		s.NoError(err)
+
+		_, err = s.contract.GetAll(s.ctx, queue)
+		s.NoError(err)
+
+		_, err = s.contract.Front(s.ctx, queue)
+		s.NoError(err)
+
+		_, err = s.contract.Stats(s.ctx, queue)
+		s.NoError(err)
+	}
+
+	s.Run("paused", func() {
+		meta, err := s.contract.Pause(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(QueueStatePaused, meta.State)
+
+		rejected(push())
+
+		_, err = s.contract.PushFront(s.ctx, queue, `{}`)
+		rejected(err)
+
+		_, err = s.contract.PushBackBatch(s.ctx, queue, `[{}]`)
+		rejected(err)
+
+		_, err = s.contract.PushBackIdempotent(s.ctx, queue, "id", `{}`)
+		rejected(err)
+
+		s.NoError(edit())
+		reads()
+
+		q, err := s.contract.PopFront(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(items[0].Key, q.Key)
+	})
+
+	s.Run("draining", func() {
+		meta, err := s.contract.Drain(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(QueueStateDraining, meta.State)
+
+		rejected(push())
+		rejected(edit())
+
+		_, err = s.contract.Swap(s.ctx, queue, items[2].Key, items[3].Key)
+		rejected(err)
+
+		_, err = s.contract.MoveTo(s.ctx, queue, items[3].Key, 0)
+		rejected(err)
+
+		_, err = s.contract.Reverse(s.ctx, queue, "", "")
+		rejected(err)
+
+		reads()
+
+		q, err := s.contract.PopFront(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(items[1].Key, q.Key)
+
+		s.NoError(s.contract.Delete(s.ctx, queue, items[2].Key))
+
+		_, err = s.contract.ConfigureQueue(s.ctx, queue, `{"max_batch":10}`)
+		s.NoError(err)
+	})
+
+	s.Run("frozen", func() {
+		meta, err := s.contract.Freeze(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(QueueStateFrozen, meta.State)
+
+		rejected(push())
+		rejected(edit())
+		rejected(s.contract.Delete(s.ctx, queue, items[3].Key))
+
+		_, err = s.contract.Swap(s.ctx, queue, items[3].Key, items[3].Key)
+		rejected(err)
+
+		_, err = s.contract.PopFront(s.ctx, queue)
+		rejected(err)
+
+		_, err = s.contract.PopN(s.ctx, queue, 1)
+		rejected(err)
+
+		_, err = s.contract.Receive(s.clientCtx("alice", mustParse("2021-05-17T11:08:53+03:00")), queue, 1, "30s")
+		rejected(err)
+
+		_, err = s.contract.PurgeExpired(s.ctx, queue, 10)
+		rejected(err)
+
+		_, err = s.contract.ConfigureQueue(s.ctx, queue, `{}`)
+		rejected(err)
+
+		rejected(s.contract.DeleteQueue(s.ctx, queue))
+
+		reads()
+
+		stats, err := s.contract.Stats(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(1, stats.Length)
+	})
+
+	s.Run("resumed", func() {
+		meta, err := s.contract.Resume(s.ctx, queue)
+		s.NoError(err)
+		s.Equal(QueueStateActive, meta.State)
+
+		s.NoError(push())
+		s.NoError(edit())
+	})
+
+	s.Run("not exists", func() {
+		_, err := s.contract.Pause(s.ctx, "state-not-exists")
+		s.True(errors.Is(err, ErrQueueNotFound))
+	})
+
+	s.NoError(s.contract.DeleteQueue(s.ctx, queue))
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-chaincode-go/shimtest"
-	"github.com/hyperledger/fabric-contract-api-go/contractapi"
 	pb "github.com/hyperledger/fabric-protos-go/peer"
 	uuid "github.com/satori/go.uuid"
 	"github.com/stretchr/testify/suite"
@@ -31,7 +30,7 @@ type Suite struct {
 	suite.Suite
 	stub     *shimtest.MockStub
 	contract *SimpleQueueContract
-	ctx      *contractapi.TransactionContext
+	ctx      *TransactionContext
 }
 
 func (s *Suite) SetupTest() {
@@ -52,7 +51,7 @@ func (s *Suite) SetupSuite() {
 	s.contract = new(SimpleQueueContract)
 	s.stub = shimtest.NewMockStub("levelDB", new(SimpleChaincode))
 
-	s.ctx = new(contractapi.TransactionContext)
+	s.ctx = new(TransactionContext)
 	s.ctx.SetStub(s.stub)
 }
 